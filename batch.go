@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// BatchCache 支持批量操作的缓存
+//
+// 可以通过 [AsBatchCache] 将普通的 [Cache] 转换为 [BatchCache]。
+type BatchCache interface {
+	Cache
+
+	// GetMulti 批量获取缓存项
+	//
+	// items 的键名为缓存项的键名，键值为接收缓存值的指针，相当于 [Cache.Get] 的 v 参数。
+	// 不存在的缓存项会从 items 中删除。
+	GetMulti(items map[string]any) error
+
+	// SetMulti 批量写入缓存项
+	//
+	// 所有的缓存项都采用相同的 ttl，其它与 [Cache.Set] 相同。
+	SetMulti(items map[string]any, ttl time.Duration) error
+
+	// DeleteMulti 批量删除缓存项
+	DeleteMulti(keys ...string) error
+}
+
+type batchCache struct {
+	Cache
+}
+
+// AsBatchCache 将 c 转换为 [BatchCache]
+//
+// 如果 c 本身已经实现了 [BatchCache]，则直接返回 c，
+// 否则返回的对象会依次调用 c 的单个操作方法。
+func AsBatchCache(c Cache) BatchCache {
+	c = unwrapContextCache(c)
+	if bc, ok := c.(BatchCache); ok {
+		return bc
+	}
+	return &batchCache{Cache: c}
+}
+
+func (c *batchCache) GetMulti(items map[string]any) error {
+	for key, v := range items {
+		switch err := c.Get(key, v); {
+		case errors.Is(err, ErrCacheMiss()):
+			delete(items, key)
+		case err != nil:
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *batchCache) SetMulti(items map[string]any, ttl time.Duration) error {
+	for key, val := range items {
+		if err := c.Set(key, val, ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *batchCache) DeleteMulti(keys ...string) error {
+	for _, key := range keys {
+		if err := c.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
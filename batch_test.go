@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache_test
+
+import (
+	"testing"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches"
+	"github.com/issue9/cache/caches/memory"
+	"github.com/issue9/cache/cachetest"
+)
+
+func TestAsBatchCache(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	a.Equal(cache.AsBatchCache(d), d)
+	a.Equal(cache.AsBatchCache(cache.AsContextCache(cachetest.Plain(d))), cache.AsBatchCache(cachetest.Plain(d)))
+
+	cachetest.Batch(a, cache.AsBatchCache(cachetest.Plain(d)))
+}
+
+func TestPrefix_Batch(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	p, ok := cache.Prefix(d, "p1").(cache.BatchCache)
+	a.True(ok).NotNil(p)
+	cachetest.Batch(a, p)
+
+	a.NotError(p.SetMulti(map[string]any{"k1": 1}, cache.Forever)).
+		True(d.Exists("p1k1"))
+
+	// 底层不支持批量操作
+	p = cache.Prefix(cachetest.Plain(d), "p2").(cache.BatchCache)
+	cachetest.Batch(a, p)
+
+	// 指定了编码方式
+	p = cache.PrefixWithCodec(d, "p3", caches.JSON).(cache.BatchCache)
+	cachetest.Batch(a, p)
+	a.NotError(p.SetMulti(map[string]any{"k1": []int{1, 2}}, cache.Forever))
+	var raw string
+	a.NotError(d.Get("p3k1", &raw)).Equal(raw, "[1,2]")
+}
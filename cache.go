@@ -1,4 +1,4 @@
-// SPDX-FileCopyrightText: 2017-2025 caixw
+// SPDX-FileCopyrightText: 2017-2026 caixw
 //
 // SPDX-License-Identifier: MIT
 
@@ -9,13 +9,17 @@
 package cache
 
 import (
+	"context"
 	"errors"
 	"time"
 
 	"github.com/issue9/localeutil"
 )
 
-var errCacheMiss = localeutil.Error("cache miss")
+var (
+	errCacheMiss = localeutil.Error("cache miss")
+	errStale     = localeutil.Error("stale cache item")
+)
 
 // Forever 永不过时
 const Forever = 0
@@ -57,9 +61,35 @@ type Cache interface {
 	// n 表示当前的数值；
 	// f 表示对数据进行操作的函数；
 	// exist 表示该元素原来是否就存在；
+	//
+	// 计数器的值不会小于零，如果需要负数，可以使用 [SignedCounterCache]。
 	Counter(key string, ttl time.Duration) (n uint64, f SetCounterFunc, exist bool, err error)
 }
 
+// ContextCache 支持 [context.Context] 的缓存访问接口
+//
+// 各方法的功能与 [Cache] 中去掉 Context 后缀的同名方法相同，
+// ctx 用于将超时和取消等信息传递给底层的驱动。
+// 可以通过 [AsContextCache] 将普通的 [Cache] 转换为 [ContextCache]。
+type ContextCache interface {
+	Cache
+
+	GetContext(ctx context.Context, key string, v any) error
+
+	SetContext(ctx context.Context, key string, val any, ttl time.Duration) error
+
+	DeleteContext(ctx context.Context, key string) error
+
+	ExistsContext(ctx context.Context, key string) bool
+
+	TouchContext(ctx context.Context, key string, ttl time.Duration) error
+
+	// CounterContext 初始化计数器
+	//
+	// ctx 同时也作用于返回的 f 。
+	CounterContext(ctx context.Context, key string, ttl time.Duration) (n uint64, f SetCounterFunc, exist bool, err error)
+}
+
 // SetCounterFunc 为计数器增加数值的函数原型
 //
 // n 为增加的数值，如果为负数，则表示减少。
@@ -68,6 +98,23 @@ type Cache interface {
 // 调用此方法也会更新元素的 TTL 值。
 type SetCounterFunc = func(n int) (uint64, error)
 
+// Codec 缓存值的编码方式
+//
+// 同一个缓存项的写入和读取需要采用相同的 [Codec]。
+// [github.com/issue9/cache/caches] 中提供了几种常用的实现。
+//
+// 计数器的值由驱动始终以十进制字符串保存，不经过 [Codec] 编码。
+//
+// [PrefixWithCodec] 以及压缩、加密等装饰器会自行编码，再将得到的 []byte 交由底层的缓存保存，
+// 所以底层缓存的 [Codec] 需要能原样保存 []byte，比如 [github.com/issue9/cache/caches.Default]。
+type Codec interface {
+	// Marshal 编码 val
+	Marshal(val any) ([]byte, error)
+
+	// Unmarshal 将 data 解码至 v，v 应该始终为指针类型。
+	Unmarshal(data []byte, v any) error
+}
+
 // Cleanable 可清除所有缓存内容的接口
 type Cleanable interface {
 	Cache
@@ -78,7 +125,8 @@ type Cleanable interface {
 
 // Driver 所有缓存驱动需要实现的接口
 //
-// 对于数据的序列化相关操作可直接调用 [caches.Marshal] 和 [caches.Unmarshal] 进行处理。
+// 对于数据的序列化相关操作可直接调用 [caches.Marshal] 和 [caches.Unmarshal] 进行处理，
+// 如果需要支持自定义的编码方式，可以接受一个 [Codec] 对象，默认值为 [caches.Default]。
 // 新的驱动可以采用 [github.com/issue9/cache/cachetest] 对接口进行测试，看是否符合要求。
 type Driver interface {
 	Cleanable
@@ -96,21 +144,60 @@ type Driver interface {
 // ErrCacheMiss 当不存在缓存项时返回的错误
 func ErrCacheMiss() error { return errCacheMiss }
 
+// ErrStale 表示返回的是旧值
+//
+// 返回此错误时，目标值已经被正确填充，但其内容可能已经过时，
+// 错误中同时也包含了导致返回旧值的原始错误。参考 [StaleCache]。
+func ErrStale() error { return errStale }
+
 // GetOrInit 获取缓存项
 //
 // 在缓存不存在时，会尝试调用 init 初始化，并调用 [Cache.Set] 存入缓存。
 //
 // key 和 v 相当于调用 [Cache.Get] 的参数；
 // 如果 [Cache.Get] 返回 [ErrCacheMiss]，那么将调用 init 方法初始化并写入缓存。
-func GetOrInit[T any](cache Cache, key string, v *T, ttl time.Duration, init func(*T) error) error {
-	switch err := cache.Get(key, v); {
+//
+// 在同一进程中，对同一缓存项的并发调用只会执行一次 init，其它调用者等待并共享其结果，
+// 包括返回的错误以及 init 中发生的 panic，通过 [Prefix] 访问时也同样有效。
+// 共享的结果为 *v 的浅复制，如果 T 包含引用类型，调用者之间需要注意并发修改的问题。
+//
+// 如果 cache 实现了 [StaleCache]，在 init 失败时会尝试返回旧值，同时返回 [ErrStale]。
+//
+// o 用于指定一些额外的行为，比如 [XFetch]。
+func GetOrInit[T any](cache Cache, key string, v *T, ttl time.Duration, init func(*T) error, o ...InitOption) error {
+	return GetOrInitContext(context.Background(), cache, key, v, ttl, func(_ context.Context, v *T) error {
+		return init(v)
+	}, o...)
+}
+
+// GetOrInitContext 带 [context.Context] 的 [GetOrInit]
+//
+// ctx 会传递给 [ContextCache] 的相关方法以及 init。
+// 合并的并发调用仅执行第一个调用者的 init，所以其它调用者的 ctx 并不会生效。
+func GetOrInitContext[T any](ctx context.Context, cache Cache, key string, v *T, ttl time.Duration, init func(context.Context, *T) error, o ...InitOption) error {
+	c := AsContextCache(cache)
+	if opt := buildInitOptions(o); opt.beta > 0 {
+		return getOrXFetch(ctx, c, cache, key, v, ttl, init, opt.beta)
+	}
+
+	switch err := c.GetContext(ctx, key, v); {
 	case err == nil:
 		return nil
 	case errors.Is(err, ErrCacheMiss()):
-		if err := init(v); err != nil {
-			return err
+		var inited bool // 仅在当前调用执行了 init 且成功时为 true
+		load := func() (any, error) {
+			if err := init(ctx, v); err != nil {
+				return nil, err
+			}
+			inited = true
+			return *v, c.SetContext(ctx, key, *v, ttl)
 		}
-		return cache.Set(key, *v, ttl)
+
+		err = loadShared(cache, key, v, load, func(t any) error { return c.GetContext(ctx, key, t) }, nil)
+		if err != nil && !inited {
+			return serveStale(cache, key, v, err)
+		}
+		return err
 	default:
 		return err
 	}
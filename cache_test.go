@@ -1,10 +1,14 @@
-// SPDX-FileCopyrightText: 2017-2025 caixw
+// SPDX-FileCopyrightText: 2017-2026 caixw
 //
 // SPDX-License-Identifier: MIT
 
 package cache_test
 
 import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -33,6 +37,102 @@ func TestGetOrInit(t *testing.T) {
 		Equal(v2, "10")
 }
 
+func TestGetOrInit_concurrent(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	var calls atomic.Int32
+	init := func(v *string) error {
+		calls.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		*v = "v"
+		return nil
+	}
+
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+
+			// 不同的 Prefix 对象指向同一个缓存项
+			c := cache.Prefix(cache.Prefix(d, "p1"), "p2")
+			if i%2 == 0 {
+				c = cache.Prefix(d, "p1p2")
+			}
+
+			var v string
+			a.NotError(cache.GetOrInit(c, "key", &v, cache.Forever, init)).Equal(v, "v")
+		}()
+	}
+	close(start)
+	wg.Wait()
+	a.Equal(calls.Load(), 1).True(d.Exists("p1p2key"))
+
+	// 错误和 panic 也会被共享
+	errTest := errors.New("test")
+	start = make(chan struct{})
+	var errs atomic.Int32
+	var panics atomic.Int32
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if recover() != nil {
+					panics.Add(1)
+				}
+			}()
+			<-start
+
+			var v int
+			err := cache.GetOrInit(d, "err", &v, cache.Forever, func(*int) error {
+				time.Sleep(100 * time.Millisecond)
+				return errTest
+			})
+			if errors.Is(err, errTest) {
+				errs.Add(1)
+			}
+
+			cache.GetOrInit(d, "panic", &v, cache.Forever, func(*int) error {
+				time.Sleep(100 * time.Millisecond)
+				panic("panic")
+			})
+		}()
+	}
+	close(start)
+	wg.Wait()
+	a.Equal(errs.Load(), 10).Equal(panics.Load(), 10).False(d.Exists("err"))
+}
+
+func TestGetOrInitContext(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	a.NotNil(d)
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "ctx")
+
+	var v1 string
+	err := cache.GetOrInitContext(ctx, d, "key", &v1, time.Second, func(ctx context.Context, v *string) error {
+		*v = ctx.Value(ctxKey{}).(string)
+		return nil
+	})
+	a.NotError(err).Equal(v1, "ctx")
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	var v2 string
+	err = cache.GetOrInitContext(canceled, d, "key", &v2, time.Second, func(ctx context.Context, v *string) error {
+		*v = "10"
+		return nil
+	})
+	a.ErrorIs(err, context.Canceled).Empty(v2)
+}
+
 func TestGet(t *testing.T) {
 	a := assert.New(t, false)
 
@@ -46,3 +146,42 @@ func TestGet(t *testing.T) {
 	v2, err := cache.Get[string](d, "v1")
 	a.NotError(err).Equal(v2, "string")
 }
+
+func TestGetOrInit_XFetch(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	var calls atomic.Int32
+	init := func(v *int) error {
+		time.Sleep(time.Millisecond)
+		*v = int(calls.Add(1))
+		return nil
+	}
+
+	// beta 足够大，每次都会提前生成。
+	var v int
+	a.NotError(cache.GetOrInit(d, "key", &v, time.Minute, init, cache.XFetch(1e9))).Equal(v, 1)
+	a.NotError(cache.GetOrInit(d, "key", &v, time.Minute, init, cache.XFetch(1e9))).Equal(v, 2)
+
+	// 提前生成失败，返回旧值。
+	failed := func(*int) error { return errors.New("failed") }
+	a.NotError(cache.GetOrInit(d, "key", &v, time.Minute, failed, cache.XFetch(1e9))).Equal(v, 2)
+
+	// beta 足够小，在过期之前不会提前生成。
+	for range 10 {
+		a.NotError(cache.GetOrInit(d, "key", &v, time.Minute, init, cache.XFetch(1e-9))).Equal(v, 2)
+	}
+
+	// Forever 不会提前生成
+	a.NotError(cache.GetOrInit(d, "forever", &v, cache.Forever, init, cache.XFetch(1e9))).Equal(v, 3)
+	a.NotError(cache.GetOrInit(d, "forever", &v, cache.Forever, init, cache.XFetch(1e9))).Equal(v, 3)
+
+	// 已过期
+	a.NotError(cache.GetOrInit(d, "expired", &v, 500*time.Millisecond, init, cache.XFetch(1e-9))).Equal(v, 4)
+	time.Sleep(time.Second)
+	a.NotError(cache.GetOrInit(d, "expired", &v, 500*time.Millisecond, init, cache.XFetch(1e-9))).Equal(v, 5)
+
+	// 未启用
+	a.NotError(cache.GetOrInit(d, "plain", &v, time.Minute, init, cache.XFetch(0))).Equal(v, 6)
+	a.NotError(cache.GetOrInit(d, "plain", &v, time.Minute, init, cache.XFetch(0))).Equal(v, 6)
+}
@@ -0,0 +1,120 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package caches
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"reflect"
+
+	"github.com/issue9/localeutil"
+
+	"github.com/issue9/cache"
+)
+
+var (
+	// Default 默认的编码方式
+	//
+	// 即 [Marshal] 和 [Unmarshal]，数值以十进制字符串保存，
+	// 时间采用 [time.RFC3339Nano] 格式，其它类型采用 GOB 编码。
+	Default cache.Codec = defaultCodec{}
+
+	// Gob 所有类型都采用 GOB 编码
+	Gob cache.Codec = gobCodec{}
+
+	// JSON 所有类型都采用 JSON 编码
+	//
+	// 方便其它语言读取缓存的内容。
+	JSON cache.Codec = jsonCodec{}
+
+	// Binary 原始的二进制编码
+	//
+	// []byte 和 string 按原样保存，数值和布尔值以大端序的定长格式保存，
+	// 其它类型需要实现 [encoding.BinaryMarshaler] 和 [encoding.BinaryUnmarshaler]。
+	Binary cache.Codec = binaryCodec{}
+)
+
+type (
+	defaultCodec struct{}
+	gobCodec     struct{}
+	jsonCodec    struct{}
+	binaryCodec  struct{}
+)
+
+func (defaultCodec) Marshal(val any) ([]byte, error) { return Marshal(val) }
+
+func (defaultCodec) Unmarshal(data []byte, v any) error { return Unmarshal(data, v) }
+
+func (gobCodec) Marshal(val any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(val); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (jsonCodec) Marshal(val any) ([]byte, error) { return json.Marshal(val) }
+
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+func (binaryCodec) Marshal(val any) ([]byte, error) {
+	switch v := val.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	case encoding.BinaryMarshaler:
+		return v.MarshalBinary()
+	case int:
+		val = int64(v)
+	case uint:
+		val = uint64(v)
+	}
+
+	if binary.Size(val) < 0 {
+		return nil, localeutil.Error("unsupported binary type %T", val)
+	}
+	return binary.Append(nil, binary.BigEndian, val)
+}
+
+func (binaryCodec) Unmarshal(data []byte, v any) error {
+	switch vv := v.(type) {
+	case *[]byte:
+		*vv = data
+		return nil
+	case *string:
+		*vv = string(data)
+		return nil
+	case encoding.BinaryUnmarshaler:
+		return vv.UnmarshalBinary(data)
+	case *int:
+		var n int64
+		if _, err := binary.Decode(data, binary.BigEndian, &n); err != nil {
+			return err
+		}
+		*vv = int(n)
+		return nil
+	case *uint:
+		var n uint64
+		if _, err := binary.Decode(data, binary.BigEndian, &n); err != nil {
+			return err
+		}
+		*vv = uint(n)
+		return nil
+	}
+
+	if rv := reflect.ValueOf(v); rv.Kind() != reflect.Pointer || binary.Size(v) < 0 {
+		return localeutil.Error("unsupported binary type %T", v)
+	}
+	_, err := binary.Decode(data, binary.BigEndian, v)
+	return err
+}
@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package caches
+
+import (
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache"
+)
+
+type object struct {
+	Name string
+	Age  int
+}
+
+func testCodec(a *assert.Assertion, c cache.Codec) {
+	bs, err := c.Marshal("str")
+	a.NotError(err)
+	var s string
+	a.NotError(c.Unmarshal(bs, &s)).Equal(s, "str")
+
+	bs, err = c.Marshal(-5)
+	a.NotError(err)
+	var i int
+	a.NotError(c.Unmarshal(bs, &i)).Equal(i, -5)
+
+	bs, err = c.Marshal(uint64(5))
+	a.NotError(err)
+	var u uint64
+	a.NotError(c.Unmarshal(bs, &u)).Equal(u, 5)
+
+	now := time.Now()
+	bs, err = c.Marshal(now)
+	a.NotError(err)
+	var t time.Time
+	a.NotError(c.Unmarshal(bs, &t)).True(t.Equal(now))
+}
+
+func TestCodec(t *testing.T) {
+	a := assert.New(t, false)
+
+	for _, c := range []cache.Codec{Default, Gob, JSON, Binary} {
+		testCodec(a, c)
+	}
+
+	for _, c := range []cache.Codec{Default, Gob, JSON} {
+		bs, err := c.Marshal(&object{Name: "n", Age: 5})
+		a.NotError(err)
+		var obj object
+		a.NotError(c.Unmarshal(bs, &obj)).Equal(obj, object{Name: "n", Age: 5})
+	}
+
+	bs, err := JSON.Marshal(&object{Name: "n", Age: 5})
+	a.NotError(err).Equal(string(bs), `{"Name":"n","Age":5}`)
+
+	bs, err = Default.Marshal(5)
+	a.NotError(err).Equal(string(bs), "5")
+}
+
+func TestBinary(t *testing.T) {
+	a := assert.New(t, false)
+
+	bs, err := Binary.Marshal(uint16(258))
+	a.NotError(err).Equal(bs, []byte{1, 2})
+	var u16 uint16
+	a.NotError(Binary.Unmarshal(bs, &u16)).Equal(u16, 258)
+
+	bs, err = Binary.Marshal(true)
+	a.NotError(err).Equal(bs, []byte{1})
+
+	bs, err = Binary.Marshal([]byte{1, 2})
+	a.NotError(err).Equal(bs, []byte{1, 2})
+
+	bs, err = Binary.Marshal(uint(5))
+	a.NotError(err)
+	var u uint
+	a.NotError(Binary.Unmarshal(bs, &u)).Equal(u, 5)
+
+	_, err = Binary.Marshal(&object{})
+	a.Error(err)
+	a.Error(Binary.Unmarshal([]byte{1}, &object{}))
+	a.Error(Binary.Unmarshal([]byte{1}, 5))
+}
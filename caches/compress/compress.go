@@ -0,0 +1,222 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+// Package compress 为缓存值提供压缩功能的装饰器
+package compress
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"time"
+
+	"github.com/issue9/localeutil"
+
+	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches"
+)
+
+// Algorithm 压缩算法
+type Algorithm byte
+
+const (
+	None Algorithm = iota // 不压缩
+	Gzip
+	Zlib
+	Flate
+)
+
+// 压缩后的内容以 magic 和表示 [Algorithm] 的一个字节开头。
+//
+// 0xff 不会出现在 UTF-8 编码的文本开头，GOB 编码中以 0xff 开头时，
+// 其后的字节表示长度，不会小于 128，即不可能是 'c'，所以不会与未经压缩的旧数据冲突。
+// 其它编码的旧数据即使以 magic 开头，也会因为无法解压而被原样返回。
+var magic = []byte{0xff, 'c', 'z'}
+
+type compressDriver struct {
+	driver    cache.Driver
+	ctx       cache.ContextCache
+	algorithm Algorithm
+	level     int
+	threshold int
+	codec     cache.Codec
+}
+
+// Option 用于设置 [New] 的参数
+type Option func(*compressDriver)
+
+// WithCodec 指定缓存值在压缩之前的编码方式
+//
+// 默认为 [caches.Default]。
+func WithCodec(c cache.Codec) Option {
+	return func(d *compressDriver) { d.codec = c }
+}
+
+// WithLevel 指定压缩级别
+//
+// 可用的值参考 [flate] 包中的常量，默认为 [flate.DefaultCompression]。
+func WithLevel(level int) Option {
+	return func(d *compressDriver) { d.level = level }
+}
+
+// New 声明带有压缩功能的缓存
+//
+// 值在编码之后，长度大于等于 threshold 的内容会采用 a 进行压缩再交由 d 保存，d 的编码方式参考 [cache.Codec]。
+// 读取时根据内容头部的标记选择解压算法，没有标记或是无法解压的内容会被当作未压缩的值，
+// 所以启用压缩之前写入的值依然可以正常读取。
+//
+// 只有 Get 和 Set 会处理值的内容，计数器以及其它的操作均直接交由 d 执行。
+func New(d cache.Driver, a Algorithm, threshold int, o ...Option) cache.Driver {
+	c := &compressDriver{
+		driver:    d,
+		ctx:       cache.AsContextCache(d),
+		algorithm: a,
+		level:     flate.DefaultCompression,
+		threshold: threshold,
+		codec:     caches.Default,
+	}
+	for _, opt := range o {
+		opt(c)
+	}
+	return c
+}
+
+func (d *compressDriver) Get(key string, v any) error {
+	return d.GetContext(context.Background(), key, v)
+}
+
+func (d *compressDriver) GetContext(ctx context.Context, key string, v any) error {
+	var bs []byte
+	if err := d.ctx.GetContext(ctx, key, &bs); err != nil {
+		return err
+	}
+
+	return d.codec.Unmarshal(decode(bs), v)
+}
+
+func (d *compressDriver) Set(key string, val any, ttl time.Duration) error {
+	return d.SetContext(context.Background(), key, val, ttl)
+}
+
+func (d *compressDriver) SetContext(ctx context.Context, key string, val any, ttl time.Duration) error {
+	data, err := d.codec.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	bs, err := d.encode(data)
+	if err != nil {
+		return err
+	}
+	return d.ctx.SetContext(ctx, key, bs, ttl)
+}
+
+func (d *compressDriver) Delete(key string) error { return d.driver.Delete(key) }
+
+func (d *compressDriver) DeleteContext(ctx context.Context, key string) error {
+	return d.ctx.DeleteContext(ctx, key)
+}
+
+func (d *compressDriver) Exists(key string) bool { return d.driver.Exists(key) }
+
+func (d *compressDriver) ExistsContext(ctx context.Context, key string) bool {
+	return d.ctx.ExistsContext(ctx, key)
+}
+
+func (d *compressDriver) Touch(key string, ttl time.Duration) error { return d.driver.Touch(key, ttl) }
+
+func (d *compressDriver) TouchContext(ctx context.Context, key string, ttl time.Duration) error {
+	return d.ctx.TouchContext(ctx, key, ttl)
+}
+
+func (d *compressDriver) Counter(key string, ttl time.Duration) (uint64, cache.SetCounterFunc, bool, error) {
+	return d.driver.Counter(key, ttl)
+}
+
+func (d *compressDriver) CounterContext(ctx context.Context, key string, ttl time.Duration) (uint64, cache.SetCounterFunc, bool, error) {
+	return d.ctx.CounterContext(ctx, key, ttl)
+}
+
+func (d *compressDriver) Clean() error { return d.driver.Clean() }
+
+func (d *compressDriver) CleanPrefix(prefix string) error { return cache.CleanPrefix(d.driver, prefix) }
+
+func (d *compressDriver) Ping() error { return d.driver.Ping() }
+
+func (d *compressDriver) Close() error { return d.driver.Close() }
+
+func (d *compressDriver) Driver() any { return d.driver.Driver() }
+
+func (d *compressDriver) encode(data []byte) ([]byte, error) {
+	if d.algorithm == None || len(data) < d.threshold {
+		if bytes.HasPrefix(data, magic) { // 避免与压缩的内容混淆
+			return append(append(bytes.Clone(magic), byte(None)), data...), nil
+		}
+		return data, nil
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, len(data)/2))
+	buf.Write(magic)
+	buf.WriteByte(byte(d.algorithm))
+
+	var w io.WriteCloser
+	var err error
+	switch d.algorithm {
+	case Gzip:
+		w, err = gzip.NewWriterLevel(buf, d.level)
+	case Zlib:
+		w, err = zlib.NewWriterLevel(buf, d.level)
+	case Flate:
+		w, err = flate.NewWriter(buf, d.level)
+	default:
+		return nil, localeutil.Error("unsupported compression algorithm %d", d.algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = w.Write(data); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// 解压 bs，没有标记或是无法解压的内容会被当作未压缩的旧数据原样返回。
+func decode(bs []byte) []byte {
+	n := len(magic)
+	if len(bs) <= n || !bytes.HasPrefix(bs, magic) {
+		return bs
+	}
+
+	var r io.ReadCloser
+	var err error
+	data := bytes.NewReader(bs[n+1:])
+	switch Algorithm(bs[n]) {
+	case None:
+		return bs[n+1:]
+	case Gzip:
+		r, err = gzip.NewReader(data)
+	case Zlib:
+		r, err = zlib.NewReader(data)
+	case Flate:
+		r = flate.NewReader(data)
+	default:
+		return bs
+	}
+	if err != nil {
+		return bs
+	}
+	defer r.Close()
+
+	if out, err := io.ReadAll(r); err == nil {
+		return out
+	}
+	return bs
+}
@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package compress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches"
+	"github.com/issue9/cache/caches/memory"
+	"github.com/issue9/cache/cachetest"
+)
+
+var (
+	_ cache.Driver          = &compressDriver{}
+	_ cache.ContextCache    = &compressDriver{}
+	_ cache.PrefixCleanable = &compressDriver{}
+)
+
+func TestCompress(t *testing.T) {
+	a := assert.New(t, false)
+
+	for _, algo := range []Algorithm{None, Gzip, Zlib, Flate} {
+		d := memory.New()
+		c := New(d, algo, 0)
+		a.NotNil(c).Equal(c.Driver(), d.Driver())
+
+		cachetest.Basic(a, c)
+		cachetest.Object(a, c)
+		cachetest.Counter(a, c)
+
+		long := strings.Repeat("hello ", 1000)
+		a.NotError(c.Set("long", long, cache.Forever))
+		var raw []byte
+		a.NotError(d.Get("long", &raw))
+		if algo == None {
+			a.Equal(string(raw), long)
+		} else {
+			a.Equal(raw[:len(magic)], magic).Equal(raw[len(magic)], algo).True(len(raw) < len(long))
+		}
+
+		var v string
+		a.NotError(c.Get("long", &v)).Equal(v, long)
+		a.NotError(c.Close())
+	}
+}
+
+func TestCompress_threshold(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	c := New(d, Gzip, 100, WithLevel(9), WithCodec(caches.JSON))
+
+	// 未超过 threshold，不压缩
+	a.NotError(c.Set("short", "short", cache.Forever))
+	var raw string
+	a.NotError(d.Get("short", &raw)).Equal(raw, `"short"`)
+
+	// 与压缩标记冲突的内容
+	conflict := append(bytes.Clone(magic), byte(Gzip))
+	a.NotError(c.Set("magic", conflict, cache.Forever))
+	var bs []byte
+	a.NotError(c.Get("magic", &bs)).Equal(bs, conflict)
+
+	// 启用压缩之前写入的内容
+	a.NotError(d.Set("legacy", `"legacy"`, cache.Forever))
+	var v string
+	a.NotError(c.Get("legacy", &v)).Equal(v, "legacy")
+
+	a.ErrorIs(c.Get("not-exists", &v), cache.ErrCacheMiss())
+
+	// 不支持的算法
+	c = New(d, Algorithm(100), 0)
+	a.Error(c.Set("k", "v", cache.Forever))
+}
+
+func TestCompress_Prefix(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	p := cache.Prefix(New(d, Gzip, 0), "p:")
+	a.NotError(p.Set("k1", 1, cache.Forever)).
+		True(d.Exists("p:k1")).
+		NotError(d.Set("k1", 1, cache.Forever))
+
+	a.NotError(p.Clean())
+	a.False(d.Exists("p:k1")).True(d.Exists("k1"))
+}
+
+func TestDecode(t *testing.T) {
+	a := assert.New(t, false)
+
+	// 不支持的算法
+	bs := append(bytes.Clone(magic), 200, 1)
+	a.Equal(decode(bs), bs)
+
+	// 无法解压
+	bs = append(bytes.Clone(magic), byte(Gzip), 1)
+	a.Equal(decode(bs), bs)
+
+	// 以 0xff 开头的旧数据
+	bs = []byte{0xff, byte(Gzip), 1}
+	a.Equal(decode(bs), bs)
+
+	d := memory.New()
+	a.NotError(d.Set("legacy", bs, cache.Forever))
+	var v []byte
+	a.NotError(New(d, Gzip, 0).Get("legacy", &v)).Equal(v, bs)
+}
@@ -0,0 +1,194 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+// Package encrypt 为缓存值提供 AES-GCM 加密功能的装饰器
+package encrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"time"
+
+	"github.com/issue9/localeutil"
+
+	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches"
+)
+
+// 格式版本，加密后的内容格式为：version + key id + nonce + ciphertext
+const version = 1
+
+var errDecrypt = localeutil.Error("failed to decrypt cache item")
+
+type encryptDriver struct {
+	driver      cache.Driver
+	ctx         cache.ContextCache
+	keys        map[byte]cipher.AEAD
+	primary     byte
+	codec       cache.Codec
+	missOnError bool
+}
+
+// Option 用于设置 [New] 的参数
+type Option func(*encryptDriver)
+
+// WithCodec 指定缓存值在加密之前的编码方式
+//
+// 默认为 [caches.Default]。
+func WithCodec(c cache.Codec) Option {
+	return func(d *encryptDriver) { d.codec = c }
+}
+
+// WithMissOnError 将无法解密的缓存项当作不存在
+//
+// 默认情况下，被篡改或是无法解密的缓存项在读取时返回 [ErrDecrypt]，
+// 启用此选项之后将返回 [cache.ErrCacheMiss]，方便 [cache.GetOrInit] 等重新生成缓存项。
+func WithMissOnError() Option {
+	return func(d *encryptDriver) { d.missOnError = true }
+}
+
+// ErrDecrypt 缓存项被篡改或是无法解密时返回的错误
+func ErrDecrypt() error { return errDecrypt }
+
+// New 声明带有加密功能的缓存
+//
+// 值在编码之后采用 AES-GCM 加密，密文以 []byte 的形式交由 d 保存（参考 [cache.Codec]）。
+// 缓存项的键名会作为附加数据参与认证，将密文复制到其它键名下也无法通过认证。
+//
+// keys 为密钥 ID 与密钥的对应关系，密钥的长度必须为 16、24 或是 32 字节；
+// primary 为加密时采用的密钥 ID，密钥 ID 会被写入密文头部，
+// 解密时根据该 ID 选择密钥，所以在更换密钥时，可以保留旧的密钥用于解密之前写入的内容。
+//
+// 计数器是以明文保存的，如果其内容需要保密，不应该通过此对象使用计数器。
+func New(d cache.Driver, primary byte, keys map[byte][]byte, o ...Option) (cache.Driver, error) {
+	e := &encryptDriver{
+		driver:  d,
+		ctx:     cache.AsContextCache(d),
+		keys:    make(map[byte]cipher.AEAD, len(keys)),
+		primary: primary,
+		codec:   caches.Default,
+	}
+
+	for id, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		if e.keys[id], err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+	if _, found := e.keys[primary]; !found {
+		return nil, localeutil.Error("primary key %d not found", primary)
+	}
+
+	for _, opt := range o {
+		opt(e)
+	}
+	return e, nil
+}
+
+func (d *encryptDriver) Get(key string, v any) error {
+	return d.GetContext(context.Background(), key, v)
+}
+
+func (d *encryptDriver) GetContext(ctx context.Context, key string, v any) error {
+	var bs []byte
+	if err := d.ctx.GetContext(ctx, key, &bs); err != nil {
+		return err
+	}
+
+	data, ok := d.decrypt(key, bs)
+	if !ok {
+		if d.missOnError {
+			return cache.ErrCacheMiss()
+		}
+		return ErrDecrypt()
+	}
+	return d.codec.Unmarshal(data, v)
+}
+
+func (d *encryptDriver) Set(key string, val any, ttl time.Duration) error {
+	return d.SetContext(context.Background(), key, val, ttl)
+}
+
+func (d *encryptDriver) SetContext(ctx context.Context, key string, val any, ttl time.Duration) error {
+	data, err := d.codec.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	bs, err := d.encrypt(key, data)
+	if err != nil {
+		return err
+	}
+	return d.ctx.SetContext(ctx, key, bs, ttl)
+}
+
+func (d *encryptDriver) Delete(key string) error { return d.driver.Delete(key) }
+
+func (d *encryptDriver) DeleteContext(ctx context.Context, key string) error {
+	return d.ctx.DeleteContext(ctx, key)
+}
+
+func (d *encryptDriver) Exists(key string) bool { return d.driver.Exists(key) }
+
+func (d *encryptDriver) ExistsContext(ctx context.Context, key string) bool {
+	return d.ctx.ExistsContext(ctx, key)
+}
+
+func (d *encryptDriver) Touch(key string, ttl time.Duration) error { return d.driver.Touch(key, ttl) }
+
+func (d *encryptDriver) TouchContext(ctx context.Context, key string, ttl time.Duration) error {
+	return d.ctx.TouchContext(ctx, key, ttl)
+}
+
+func (d *encryptDriver) Counter(key string, ttl time.Duration) (uint64, cache.SetCounterFunc, bool, error) {
+	return d.driver.Counter(key, ttl)
+}
+
+func (d *encryptDriver) CounterContext(ctx context.Context, key string, ttl time.Duration) (uint64, cache.SetCounterFunc, bool, error) {
+	return d.ctx.CounterContext(ctx, key, ttl)
+}
+
+func (d *encryptDriver) Clean() error { return d.driver.Clean() }
+
+func (d *encryptDriver) CleanPrefix(prefix string) error { return cache.CleanPrefix(d.driver, prefix) }
+
+func (d *encryptDriver) Ping() error { return d.driver.Ping() }
+
+func (d *encryptDriver) Close() error { return d.driver.Close() }
+
+func (d *encryptDriver) Driver() any { return d.driver.Driver() }
+
+func (d *encryptDriver) encrypt(key string, data []byte) ([]byte, error) {
+	aead := d.keys[d.primary]
+
+	bs := make([]byte, 2+aead.NonceSize(), 2+aead.NonceSize()+len(data)+aead.Overhead())
+	bs[0] = version
+	bs[1] = d.primary
+	nonce := bs[2:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(bs, nonce, data, []byte(key)), nil
+}
+
+func (d *encryptDriver) decrypt(key string, bs []byte) ([]byte, bool) {
+	if len(bs) < 2 || bs[0] != version {
+		return nil, false
+	}
+
+	aead, found := d.keys[bs[1]]
+	if !found || len(bs) < 2+aead.NonceSize() {
+		return nil, false
+	}
+
+	nonce, ciphertext := bs[2:2+aead.NonceSize()], bs[2+aead.NonceSize():]
+	data, err := aead.Open(nil, nonce, ciphertext, []byte(key))
+	return data, err == nil
+}
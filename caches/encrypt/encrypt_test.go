@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package encrypt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches"
+	"github.com/issue9/cache/caches/memory"
+	"github.com/issue9/cache/cachetest"
+)
+
+var (
+	_ cache.Driver          = &encryptDriver{}
+	_ cache.ContextCache    = &encryptDriver{}
+	_ cache.PrefixCleanable = &encryptDriver{}
+)
+
+var (
+	key1 = bytes.Repeat([]byte{1}, 16)
+	key2 = bytes.Repeat([]byte{2}, 32)
+)
+
+func TestNew(t *testing.T) {
+	a := assert.New(t, false)
+
+	c, err := New(memory.New(), 1, map[byte][]byte{1: []byte("short")})
+	a.Error(err).Nil(c)
+
+	c, err = New(memory.New(), 2, map[byte][]byte{1: key1})
+	a.Error(err).Nil(c)
+}
+
+func TestEncrypt(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	c, err := New(d, 1, map[byte][]byte{1: key1}, WithCodec(caches.JSON))
+	a.NotError(err).NotNil(c).Equal(c.Driver(), d.Driver())
+
+	cachetest.Basic(a, c)
+	cachetest.Object(a, c)
+	cachetest.Counter(a, c)
+
+	a.NotError(c.Set("k1", "secret", cache.Forever))
+	var raw []byte
+	a.NotError(d.Get("k1", &raw))
+	a.Equal(raw[0], version).Equal(raw[1], 1).False(bytes.Contains(raw, []byte("secret")))
+
+	var v string
+	a.NotError(c.Get("k1", &v)).Equal(v, "secret")
+
+	// 篡改内容
+	raw[len(raw)-1]++
+	a.NotError(d.Set("k1", raw, cache.Forever))
+	a.ErrorIs(c.Get("k1", &v), ErrDecrypt())
+
+	// 复制到其它键名
+	a.NotError(c.Set("k1", "secret", cache.Forever))
+	a.NotError(d.Get("k1", &raw))
+	a.NotError(d.Set("k2", raw, cache.Forever))
+	a.ErrorIs(c.Get("k2", &v), ErrDecrypt())
+
+	// 未加密的内容
+	a.NotError(d.Set("plain", "plain", cache.Forever))
+	a.ErrorIs(c.Get("plain", &v), ErrDecrypt())
+
+	a.ErrorIs(c.Get("not-exists", &v), cache.ErrCacheMiss())
+}
+
+func TestEncrypt_rotation(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	c1, err := New(d, 1, map[byte][]byte{1: key1})
+	a.NotError(err)
+	a.NotError(c1.Set("k1", "v1", cache.Forever))
+
+	// 更换主密钥，旧的密钥依然用于解密。
+	c2, err := New(d, 2, map[byte][]byte{1: key1, 2: key2})
+	a.NotError(err)
+	var v string
+	a.NotError(c2.Get("k1", &v)).Equal(v, "v1")
+	a.NotError(c2.Set("k2", "v2", cache.Forever))
+	var raw []byte
+	a.NotError(d.Get("k2", &raw)).Equal(raw[1], 2)
+
+	// 移除旧的密钥
+	c3, err := New(d, 2, map[byte][]byte{2: key2}, WithMissOnError())
+	a.NotError(err)
+	a.ErrorIs(c3.Get("k1", &v), cache.ErrCacheMiss())
+	a.NotError(c3.Get("k2", &v)).Equal(v, "v2")
+}
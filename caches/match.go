@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package caches
+
+// Match 判断 key 是否与 pattern 匹配
+//
+// pattern 的规则与 [github.com/issue9/cache.Scanner.Keys] 相同，
+// 与 redis 一样以字节为单位进行匹配，方便驱动实现 [github.com/issue9/cache.Scanner]。
+func Match(pattern, key string) bool {
+	px, kx := 0, 0
+	starPx, starKx := -1, -1 // 最近一个 * 的位置以及回溯时 key 的位置
+	for px < len(pattern) || kx < len(key) {
+		if px < len(pattern) {
+			switch c := pattern[px]; c {
+			case '*':
+				starPx, starKx = px, kx+1
+				px++
+				continue
+			case '?':
+				if kx < len(key) {
+					px++
+					kx++
+					continue
+				}
+			case '[':
+				if kx < len(key) {
+					if ok, n := matchClass(pattern[px:], key[kx]); ok {
+						px += n
+						kx++
+						continue
+					}
+				}
+			case '\\':
+				if px+1 < len(pattern) {
+					if kx < len(key) && key[kx] == pattern[px+1] {
+						px += 2
+						kx++
+						continue
+					}
+					break
+				}
+				fallthrough // 末尾的 \ 作为普通字符
+			default:
+				if kx < len(key) && key[kx] == c {
+					px++
+					kx++
+					continue
+				}
+			}
+		}
+
+		if starPx >= 0 && starKx <= len(key) {
+			px, kx = starPx+1, starKx
+			starKx++
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// 判断 c 是否与以 [ 开头的 p 匹配，返回值 n 为 [] 部分的长度。
+//
+// 与 redis 相同，没有结束符 ] 时匹配到 p 的末尾。
+func matchClass(p string, c byte) (matched bool, n int) {
+	i := 1
+	not := i < len(p) && p[i] == '^'
+	if not {
+		i++
+	}
+
+	for ; i < len(p); i++ {
+		switch {
+		case p[i] == ']':
+			return matched != not, i + 1
+		case p[i] == '\\' && i+1 < len(p):
+			i++
+			if p[i] == c {
+				matched = true
+			}
+		case i+2 < len(p) && p[i+1] == '-' && p[i+2] != ']':
+			start, end := p[i], p[i+2]
+			if start > end {
+				start, end = end, start
+			}
+			if c >= start && c <= end {
+				matched = true
+			}
+			i += 2
+		case p[i] == c:
+			matched = true
+		}
+	}
+	return matched != not, len(p)
+}
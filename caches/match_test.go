@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package caches
+
+import (
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestMatch(t *testing.T) {
+	a := assert.New(t, false)
+
+	data := []struct {
+		pattern, key string
+		matched      bool
+	}{
+		{"", "", true},
+		{"", "a", false},
+		{"*", "", true},
+		{"*", "abc", true},
+		{"abc", "abc", true},
+		{"abc", "abcd", false},
+		{"a*", "abc", true},
+		{"a*", "bac", false},
+		{"*c", "abc", true},
+		{"a*c", "ac", true},
+		{"a*c", "abbbc", true},
+		{"a*c", "abcd", false},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+		{"**", "abc", true},
+		{"a?c", "abc", true},
+		{"a?c", "ac", false},
+		{"?", "", false},
+		{"[abc]", "b", true},
+		{"[abc]", "d", false},
+		{"[^abc]", "d", true},
+		{"[^abc]", "a", false},
+		{"[a-c]x", "bx", true},
+		{"[c-a]x", "bx", true},
+		{"[a-c]x", "dx", false},
+		{"[a-]", "-", true},
+		{"[\\]]", "]", true},
+		{"[abc", "b", true},
+		{"a\\*", "a*", true},
+		{"a\\*", "ab", false},
+		{"a\\?", "a?", true},
+		{"a\\", "a\\", true},
+		{"user:*:name", "user:1:name", true},
+		{"user:*:name", "user:1:age", false},
+		{"前缀*", "前缀abc", true},
+	}
+
+	for _, item := range data {
+		a.Equal(Match(item.pattern, item.key), item.matched, "%s %s", item.pattern, item.key)
+	}
+}
@@ -1,4 +1,4 @@
-// SPDX-FileCopyrightText: 2017-2024 caixw
+// SPDX-FileCopyrightText: 2017-2026 caixw
 //
 // SPDX-License-Identifier: MIT
 
@@ -6,7 +6,10 @@
 package memcache
 
 import (
+	"bytes"
 	"errors"
+	"iter"
+	"strconv"
 	"time"
 
 	"github.com/bradfitz/gomemcache/memcache"
@@ -16,16 +19,28 @@ import (
 )
 
 type memcacheDriver struct {
-	client *memcache.Client
+	client   *memcache.Client
+	codec    cache.Codec
+	metadata bool
 }
 
 // New 声明基于 [memcached] 的缓存系统
 //
 // [cache.Driver.Driver] 的返回类型为 [memcache.Client]。
 //
+// 返回对象同时实现了 [cache.BatchCache]，其中 GetMulti 采用 [memcache.Client.GetMulti] 实现；
+// 以及 [cache.ConditionalCache]，均采用 memcached 的原生命令实现。
+//
 // [memcached]: https://memcached.org/
-func New(addr ...string) cache.Driver {
-	return &memcacheDriver{client: memcache.New(addr...)}
+func New(addr ...string) cache.Driver { return NewFromClient(memcache.New(addr...)) }
+
+// NewFromClient 从 [memcache.Client] 声明缓存系统
+func NewFromClient(c *memcache.Client, o ...Option) cache.Driver {
+	d := &memcacheDriver{client: c, codec: caches.Default}
+	for _, opt := range o {
+		opt(d)
+	}
+	return d
 }
 
 func (d *memcacheDriver) Get(key string, val any) error {
@@ -36,20 +51,105 @@ func (d *memcacheDriver) Get(key string, val any) error {
 		return err
 	}
 
-	return caches.Unmarshal(item.Value, val)
+	_, bs := splitMetadata(item)
+	return d.codec.Unmarshal(bs, val)
 }
 
 func (d *memcacheDriver) Set(key string, val any, ttl time.Duration) error {
-	bs, err := caches.Marshal(val)
+	item, err := d.newItem(key, val, ttl)
 	if err != nil {
 		return err
 	}
+	return d.client.Set(item)
+}
 
-	return d.client.Set(&memcache.Item{
-		Key:        key,
-		Value:      bs,
-		Expiration: int32(ttl.Seconds()),
-	})
+// memcached 将大于此值的过期时间视为 unix 时间戳
+const maxRelativeExpiration = 30 * 24 * time.Hour
+
+// 将 ttl 转换为 memcached 的过期时间
+//
+// memcached 以秒为单位且 0 表示永不过期，所以不足一秒的 ttl 向上取整，
+// 超过 30 天的 ttl 则转换为 unix 时间戳。
+func expiration(ttl time.Duration) int32 {
+	switch {
+	case ttl <= 0:
+		return 0
+	case ttl > maxRelativeExpiration:
+		return int32(time.Now().Add(ttl).Unix())
+	default:
+		return int32((ttl + time.Second - 1) / time.Second)
+	}
+}
+
+func (d *memcacheDriver) newItem(key string, val any, ttl time.Duration) (*memcache.Item, error) {
+	bs, err := d.codec.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &memcache.Item{Key: key, Value: bs, Expiration: expiration(ttl)}
+	if d.metadata {
+		item.Value = newMetadata(ttl).append(make([]byte, 0, metadataSize+len(bs)))
+		item.Value = append(item.Value, bs...)
+		item.Flags = metadataFlag
+	}
+	return item, nil
+}
+
+func (d *memcacheDriver) Add(key string, val any, ttl time.Duration) error {
+	item, err := d.newItem(key, val, ttl)
+	if err != nil {
+		return err
+	}
+	return notStored(d.client.Add(item))
+}
+
+func (d *memcacheDriver) Replace(key string, val any, ttl time.Duration) error {
+	item, err := d.newItem(key, val, ttl)
+	if err != nil {
+		return err
+	}
+	return notStored(d.client.Replace(item))
+}
+
+func (d *memcacheDriver) GetCAS(key string, v any) (uint64, error) {
+	item, err := d.client.Get(key)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return 0, cache.ErrCacheMiss()
+	} else if err != nil {
+		return 0, err
+	}
+
+	_, bs := splitMetadata(item)
+	return item.CasID, d.codec.Unmarshal(bs, v)
+}
+
+func (d *memcacheDriver) CompareAndSwap(key string, val any, token uint64, ttl time.Duration) error {
+	item, err := d.newItem(key, val, ttl)
+	if err != nil {
+		return err
+	}
+	item.CasID = token
+	return notStored(d.client.CompareAndSwap(item))
+}
+
+// CompareAndDelete 仅在缓存项的版本标记依然为 token 时删除
+//
+// memcached 并没有对应的命令，以过期时间为负数的 CompareAndSwap 代替。
+func (d *memcacheDriver) CompareAndDelete(key string, token uint64) error {
+	return notStored(d.client.CompareAndSwap(&memcache.Item{Key: key, CasID: token, Expiration: -1}))
+}
+
+// 将 memcache 中表示条件不满足的错误转换为 [cache.ErrNotStored]
+func notStored(err error) error {
+	switch {
+	case errors.Is(err, memcache.ErrNotStored), errors.Is(err, memcache.ErrCASConflict):
+		return cache.ErrNotStored()
+	case errors.Is(err, memcache.ErrCacheMiss):
+		return cache.ErrCacheMiss()
+	default:
+		return err
+	}
 }
 
 func (d *memcacheDriver) Delete(key string) error {
@@ -59,11 +159,72 @@ func (d *memcacheDriver) Delete(key string) error {
 	return nil
 }
 
+func (d *memcacheDriver) GetMulti(items map[string]any) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+
+	found, err := d.client.GetMulti(keys)
+	if err != nil {
+		return err
+	}
+
+	for key, v := range items {
+		item, ok := found[key]
+		if !ok {
+			delete(items, key)
+			continue
+		}
+
+		_, bs := splitMetadata(item)
+		if err := d.codec.Unmarshal(bs, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetMulti 批量写入缓存项
+//
+// memcached 并不支持批量写入，此方法依次写入每一项。
+func (d *memcacheDriver) SetMulti(items map[string]any, ttl time.Duration) error {
+	for key, val := range items {
+		if err := d.Set(key, val, ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteMulti 批量删除缓存项
+//
+// memcached 并不支持批量删除，此方法依次删除每一项。
+func (d *memcacheDriver) DeleteMulti(keys ...string) error {
+	for _, key := range keys {
+		if err := d.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (d *memcacheDriver) Exists(key string) bool {
 	_, err := d.client.Get(key)
 	return err == nil || !errors.Is(err, memcache.ErrCacheMiss)
 }
 
+// Keys 遍历键名
+//
+// memcached 并不支持遍历键名，始终产生 [errors.ErrUnsupported]。
+func (d *memcacheDriver) Keys(string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) { yield("", errors.ErrUnsupported) }
+}
+
 func (d *memcacheDriver) Clean() error { return d.client.DeleteAll() }
 
 func (d *memcacheDriver) Close() error { return d.client.Close() }
@@ -73,17 +234,25 @@ func (d *memcacheDriver) Driver() any { return d.client }
 func (d *memcacheDriver) Ping() error { return d.client.Ping() }
 
 func (d *memcacheDriver) Touch(key string, ttl time.Duration) (err error) {
-	if err = d.client.Touch(key, int32(ttl.Seconds())); errors.Is(err, memcache.ErrCacheMiss) {
+	done := false
+	if d.metadata {
+		done, err = d.touchMetadata(key, ttl)
+	}
+	if !done {
+		err = d.client.Touch(key, expiration(ttl))
+	}
+
+	if errors.Is(err, memcache.ErrCacheMiss) {
 		err = nil
 	}
 	return err
 }
 
 func (d *memcacheDriver) Counter(key string, ttl time.Duration) (n uint64, f cache.SetCounterFunc, exist bool, err error) {
-	t := int32(ttl.Seconds())
+	t := expiration(ttl)
 
-	if n, err = cache.Get[uint64](d, key); errors.Is(err, cache.ErrCacheMiss()) {
-		err = d.Set(key, 0, ttl)
+	if n, err = d.getCounter(key); errors.Is(err, cache.ErrCacheMiss()) {
+		err = d.client.Set(&memcache.Item{Key: key, Value: []byte("0"), Expiration: t})
 		n = 0
 	} else {
 		exist = true
@@ -95,7 +264,7 @@ func (d *memcacheDriver) Counter(key string, ttl time.Duration) (n uint64, f cac
 	return n, func(n int) (uint64, error) {
 		switch {
 		default: // n == 0
-			return cache.Get[uint64](d, key)
+			return d.getCounter(key)
 		case n > 0:
 			v, err := d.client.Increment(key, uint64(n))
 			if err == nil && t > 0 {
@@ -119,3 +288,80 @@ func (d *memcacheDriver) Counter(key string, ttl time.Duration) (n uint64, f cac
 		}
 	}, exist, nil
 }
+
+// SignedCounter 实现 [cache.SignedCounterCache] 接口
+//
+// memcached 的 incr 和 decr 仅支持无符号的数值，所以由 CAS 模拟，
+// 多次冲突之后 f 返回 [memcache.ErrCASConflict]。
+func (d *memcacheDriver) SignedCounter(key string, ttl time.Duration, lower, upper int64) (n int64, f cache.SetSignedCounterFunc, exist bool, err error) {
+	if err = caches.CheckSignedRange(lower, upper); err != nil {
+		return 0, nil, false, err
+	}
+	t := expiration(ttl)
+
+	if n, _, err = d.getSignedCounter(key); errors.Is(err, cache.ErrCacheMiss()) {
+		n = caches.SignedZero(lower, upper)
+		err = d.client.Add(&memcache.Item{Key: key, Value: []byte(strconv.FormatInt(n, 10)), Expiration: t})
+		if errors.Is(err, memcache.ErrNotStored) { // 已被其它进程初始化
+			n, _, err = d.getSignedCounter(key)
+			exist = true
+		}
+	} else {
+		exist = true
+	}
+	if err != nil {
+		return 0, nil, false, err
+	}
+
+	return n, func(n int64) (v int64, err error) {
+		for range casRetries {
+			var item *memcache.Item
+			if v, item, err = d.getSignedCounter(key); err != nil || n == 0 {
+				return v, err
+			}
+
+			nv, err := caches.AddSigned(v, n, lower, upper)
+			if err != nil {
+				return v, err
+			}
+
+			item.Value = []byte(strconv.FormatInt(nv, 10))
+			item.Expiration = t
+			switch err = d.client.CompareAndSwap(item); {
+			case errors.Is(err, memcache.ErrCASConflict):
+				continue
+			case errors.Is(err, memcache.ErrCacheMiss), errors.Is(err, memcache.ErrNotStored):
+				return 0, cache.ErrCacheMiss()
+			default:
+				return nv, err
+			}
+		}
+		return v, memcache.ErrCASConflict
+	}, exist, nil
+}
+
+// 获取有符号计数器的值以及对应的 [memcache.Item]
+func (d *memcacheDriver) getSignedCounter(key string) (int64, *memcache.Item, error) {
+	item, err := d.client.Get(key)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return 0, nil, cache.ErrCacheMiss()
+	} else if err != nil {
+		return 0, nil, err
+	}
+
+	n, err := strconv.ParseInt(string(bytes.TrimSpace(item.Value)), 10, 64)
+	return n, item, err
+}
+
+// 获取计数器的值
+//
+// memcached 在减少数值时不会改变值的长度，而是以空格填充，所以需要去掉空格。
+func (d *memcacheDriver) getCounter(key string) (uint64, error) {
+	item, err := d.client.Get(key)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return 0, cache.ErrCacheMiss()
+	} else if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(string(bytes.TrimSpace(item.Value)), 10, 64)
+}
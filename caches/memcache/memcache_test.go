@@ -1,19 +1,30 @@
-// SPDX-FileCopyrightText: 2017-2024 caixw
+// SPDX-FileCopyrightText: 2017-2026 caixw
 //
 // SPDX-License-Identifier: MIT
 
 package memcache
 
 import (
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/bradfitz/gomemcache/memcache"
 	"github.com/issue9/assert/v4"
 
 	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches"
 	"github.com/issue9/cache/cachetest"
 )
 
-var _ cache.Cache = &memcacheDriver{}
+var (
+	_ cache.Cache              = &memcacheDriver{}
+	_ cache.Scanner            = &memcacheDriver{}
+	_ cache.ItemCache          = &memcacheDriver{}
+	_ cache.ConditionalCache   = &memcacheDriver{}
+	_ cache.BatchCache         = &memcacheDriver{}
+	_ cache.SignedCounterCache = &memcacheDriver{}
+)
 
 func BenchmarkMemcache(b *testing.B) {
 	a := assert.New(b, false)
@@ -34,6 +45,7 @@ func TestMemcache(t *testing.T) {
 	cachetest.Basic(a, c)
 	cachetest.Object(a, c)
 	cachetest.Counter(a, c)
+	cachetest.Batch(a, c.(cache.BatchCache))
 
 	a.NotError(c.Close())
 }
@@ -51,3 +63,110 @@ func TestMemcache_Close(t *testing.T) {
 	var val string
 	a.NotError(c.Get("key", &val)).Equal(val, "val")
 }
+
+func TestExpiration(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.Equal(expiration(cache.Forever), 0).
+		Equal(expiration(-time.Second), 0).
+		Equal(expiration(time.Nanosecond), 1).
+		Equal(expiration(500*time.Millisecond), 1).
+		Equal(expiration(time.Second), 1).
+		Equal(expiration(1500*time.Millisecond), 2).
+		Equal(expiration(maxRelativeExpiration), 30*24*3600)
+
+	ttl := maxRelativeExpiration + time.Hour
+	start := time.Now().Add(ttl).Unix()
+	exp := int64(expiration(ttl))
+	a.True(exp >= start && exp <= time.Now().Add(ttl).Unix())
+}
+
+func TestMemcache_codec(t *testing.T) {
+	a := assert.New(t, false)
+
+	c := NewFromClient(memcache.New("localhost:11211"), WithCodec(caches.JSON))
+	a.NotNil(c)
+
+	cachetest.Basic(a, c)
+	cachetest.Object(a, c)
+	cachetest.Counter(a, c)
+
+	a.NotError(c.Close())
+}
+
+func TestMemcache_Prefix(t *testing.T) {
+	a := assert.New(t, false)
+
+	c := New("localhost:11211")
+
+	// 无法遍历键名，Prefix 以版本号区分
+	p1 := cache.Prefix(c, "p1:")
+	p2 := cache.Prefix(c, "p2:")
+	a.NotError(p1.Set("k1", 1, cache.Forever)).
+		NotError(p2.Set("k1", 2, cache.Forever)).
+		False(c.Exists("p1:k1"))
+	a.NotError(p1.Clean())
+	a.False(p1.Exists("k1")).True(p2.Exists("k1"))
+
+	// Namespace
+	n1 := cache.Namespace(c, "n1:", 0)
+	n2 := cache.Namespace(c, "n2:", 0)
+	a.NotError(n1.Set("k1", 1, cache.Forever)).
+		NotError(n2.Set("k1", 2, cache.Forever))
+
+	a.NotError(n1.Clean())
+	a.False(n1.Exists("k1")).True(n2.Exists("k1"))
+
+	a.NotError(c.Clean()).NotError(c.Close())
+}
+
+func TestMemcache_Keys(t *testing.T) {
+	a := assert.New(t, false)
+
+	c := New("localhost:11211").(cache.Scanner)
+	for k, err := range c.Keys("*") {
+		a.ErrorIs(err, errors.ErrUnsupported).Empty(k)
+	}
+}
+
+func TestMemcache_GetItem(t *testing.T) {
+	a := assert.New(t, false)
+
+	c := NewFromClient(memcache.New("localhost:11211"), WithMetadata())
+	cachetest.Basic(a, c)
+	cachetest.Counter(a, c)
+	cachetest.Item(a, c, true)
+
+	// 未启用元数据
+	c2 := New("localhost:11211")
+	a.NotError(c2.Set("k1", 1, cache.Forever))
+	var v int
+	item, err := c2.(cache.ItemCache).GetItem("k1", &v)
+	a.NotError(err).Equal(v, 1).Equal(item.TTL, -1)
+
+	// 可以读取带元数据的值
+	a.NotError(c.Set("k2", 2, cache.Forever))
+	a.NotError(c2.Get("k2", &v)).Equal(v, 2)
+
+	a.NotError(c.Clean()).NotError(c.Close())
+}
+
+func TestMemcache_Conditional(t *testing.T) {
+	a := assert.New(t, false)
+
+	c := New("localhost:11211")
+	cachetest.Conditional(a, c)
+	a.NotError(c.Close())
+
+	c = NewFromClient(memcache.New("localhost:11211"), WithMetadata())
+	cachetest.Conditional(a, c)
+	a.NotError(c.Close())
+}
+
+func TestMemcache_SignedCounter(t *testing.T) {
+	a := assert.New(t, false)
+
+	c := New("localhost:11211")
+	cachetest.SignedCounter(a, c)
+	a.NotError(c.Close())
+}
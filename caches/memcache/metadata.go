@@ -0,0 +1,147 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package memcache
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+
+	"github.com/issue9/cache"
+)
+
+const (
+	// 表示值带有元数据的标记
+	metadataFlag uint32 = 1 << 0
+
+	// 元数据的长度，依次为写入时间、过期时间和 ttl，过期时间为 0 表示永不过期。
+	metadataSize = 24
+
+	// Touch 和有符号计数器在 CAS 冲突时的重试次数
+	casRetries = 3
+)
+
+// 元数据
+type metadata struct {
+	created time.Time
+	expire  time.Time // 为零表示永不过期
+	dur     time.Duration
+}
+
+func newMetadata(ttl time.Duration) *metadata {
+	now := time.Now()
+	m := &metadata{created: now, dur: ttl}
+	if ttl > 0 {
+		m.expire = now.Add(ttl)
+	}
+	return m
+}
+
+func (m *metadata) append(bs []byte) []byte {
+	var expire int64
+	if !m.expire.IsZero() {
+		expire = m.expire.UnixNano()
+	}
+
+	bs = binary.BigEndian.AppendUint64(bs, uint64(m.created.UnixNano()))
+	bs = binary.BigEndian.AppendUint64(bs, uint64(expire))
+	return binary.BigEndian.AppendUint64(bs, uint64(m.dur))
+}
+
+func (m *metadata) item(now time.Time) *cache.Item {
+	var ttl time.Duration = cache.Forever
+	if !m.expire.IsZero() {
+		ttl = max(m.expire.Sub(now), time.Nanosecond) // 避免与 Forever 混淆
+	}
+	return &cache.Item{TTL: ttl, OriginalTTL: m.dur, Created: m.created}
+}
+
+// 分离元数据与值，如果不包含元数据，返回的元数据为空。
+func splitMetadata(item *memcache.Item) (*metadata, []byte) {
+	if item.Flags&metadataFlag == 0 || len(item.Value) < metadataSize {
+		return nil, item.Value
+	}
+
+	bs := item.Value
+	m := &metadata{
+		created: time.Unix(0, int64(binary.BigEndian.Uint64(bs))),
+		dur:     time.Duration(binary.BigEndian.Uint64(bs[16:])),
+	}
+	if expire := int64(binary.BigEndian.Uint64(bs[8:])); expire > 0 {
+		m.expire = time.Unix(0, expire)
+	}
+	return m, bs[metadataSize:]
+}
+
+// GetItem 获取缓存项的值以及元数据
+//
+// 仅在写入时启用了 [WithMetadata] 的缓存项才有完整的元数据，
+// 否则 [cache.Item] 中的时长均为 -1，时间为零值。
+func (d *memcacheDriver) GetItem(key string, v any) (*cache.Item, error) {
+	item, err := d.client.Get(key)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return nil, cache.ErrCacheMiss()
+	} else if err != nil {
+		return nil, err
+	}
+
+	m, bs := splitMetadata(item)
+	if err := d.codec.Unmarshal(bs, v); err != nil {
+		return nil, err
+	}
+
+	if m == nil {
+		return &cache.Item{TTL: -1, OriginalTTL: -1}, nil
+	}
+	return m.item(time.Now()), nil
+}
+
+// TTL 获取缓存项剩余的有效时间
+//
+// 未启用 [WithMetadata] 写入的缓存项返回 -1。
+func (d *memcacheDriver) TTL(key string) (time.Duration, error) {
+	item, err := d.client.Get(key)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return 0, cache.ErrCacheMiss()
+	} else if err != nil {
+		return 0, err
+	}
+
+	if m, _ := splitMetadata(item); m != nil {
+		return m.item(time.Now()).TTL, nil
+	}
+	return -1, nil
+}
+
+// 更新带有元数据的缓存项的过期时间，返回 false 表示该缓存项不带元数据。
+//
+// 多次冲突之后返回 [memcache.ErrCASConflict]，而不是退化为普通的 Touch，以免元数据中的过期时间与实际不符。
+func (d *memcacheDriver) touchMetadata(key string, ttl time.Duration) (bool, error) {
+	var err error
+	for range casRetries {
+		var item *memcache.Item
+		item, err = d.client.Get(key)
+		if err != nil {
+			return true, err
+		}
+
+		m, bs := splitMetadata(item)
+		if m == nil {
+			return false, nil
+		}
+
+		nm := newMetadata(ttl)
+		nm.created = m.created
+		item.Value = nm.append(make([]byte, 0, metadataSize+len(bs)))
+		item.Value = append(item.Value, bs...)
+		item.Expiration = expiration(ttl)
+		if err = d.client.CompareAndSwap(item); !errors.Is(err, memcache.ErrCASConflict) {
+			return true, err
+		}
+	}
+	return true, err
+}
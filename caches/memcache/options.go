@@ -0,0 +1,28 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package memcache
+
+import "github.com/issue9/cache"
+
+// Option 用于设置 [NewFromClient] 的参数
+type Option func(*memcacheDriver)
+
+// WithCodec 指定缓存值的编码方式
+//
+// 默认为 [caches.Default]。
+func WithCodec(c cache.Codec) Option {
+	return func(d *memcacheDriver) { d.codec = c }
+}
+
+// WithMetadata 在缓存值中保存元数据
+//
+// memcached 无法查询缓存项的过期时间，启用此选项之后，
+// 通过 [cache.Cache.Set] 写入的值会带上写入时间和过期时间，并以 Flags 字段标记，
+// 之后可以通过 [cache.ItemCache] 获取，[cache.Cache.Touch] 也会以 CAS 的方式同时更新元数据。
+// 带有元数据的值无法再作为计数器使用，计数器本身不受影响。
+// 无论是否启用，带有元数据的值都可以被正常读取，但是未启用时 Touch 不会更新其中的元数据。
+func WithMetadata() Option {
+	return func(d *memcacheDriver) { d.metadata = true }
+}
@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package memory
+
+import "time"
+
+// 在后台每隔 interval 执行一次 f，直到调用 [memoryDriver.stopTasks]。
+func (d *memoryDriver) every(interval time.Duration, f func(now time.Time)) {
+	if d.stop == nil {
+		d.stop = make(chan struct{})
+	}
+
+	d.tasks.Add(1)
+	go func() {
+		defer d.tasks.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-d.stop:
+				return
+			case now := <-ticker.C:
+				f(now)
+			}
+		}
+	}()
+}
+
+// 停止所有后台任务并等待其退出
+func (d *memoryDriver) stopTasks() {
+	if d.stop == nil {
+		return
+	}
+	close(d.stop)
+	d.tasks.Wait()
+}
+
+// 回收所有在 now 之前过期的缓存项
+func (d *memoryDriver) sweep(now time.Time) {
+	for _, s := range d.shards {
+		d.expired.Add(uint64(s.sweep(now)))
+	}
+}
@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache"
+)
+
+// 所有分片中缓存项的数量，包括已经过期但是未被回收的项。
+func count(d Driver) (n int) {
+	for _, s := range d.(*memoryDriver).shards {
+		s.mu.RLock()
+		n += len(s.items)
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+func TestJanitor(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := New(WithJanitor(50 * time.Millisecond))
+	a.NotNil(d)
+
+	a.NotError(d.Set("k1", 1, 100*time.Millisecond)).
+		NotError(d.Set("k2", 2, 100*time.Millisecond)).
+		NotError(d.Set("k3", 3, time.Hour))
+	a.Zero(d.Stats().Expired)
+
+	time.Sleep(300 * time.Millisecond)
+	a.Equal(d.Stats().Expired, 2)
+
+	// 未经访问，已经被后台清除。
+	a.Equal(count(d), 1)
+
+	a.NotError(d.Close()).
+		NotError(d.Close()) // 多次调用
+}
+
+func TestMemoryDriver_sweep(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := New().(*memoryDriver)
+	a.Nil(d.stop)
+
+	a.NotError(d.Set("k1", 1, time.Second)).
+		NotError(d.Set("k2", 2, cache.Forever))
+
+	d.sweep(time.Now())
+	a.Zero(d.Stats().Expired)
+
+	d.sweep(time.Now().Add(2 * time.Second))
+	a.Equal(d.Stats().Expired, 1).
+		False(d.Exists("k1")).
+		True(d.Exists("k2"))
+}
@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package memory
+
+import (
+	"container/list"
+	"sync"
+)
+
+type lru struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int64
+	size       int64
+	ll         *list.List
+	elems      map[string]*list.Element
+}
+
+type lruEntry struct {
+	key  string
+	size int
+}
+
+// NewLRU 声明最近最少使用（LRU）的淘汰策略
+//
+// maxEntries 和 maxBytes 分别表示最大的缓存项数量和字节数，小于等于 0 表示不限制。
+func NewLRU(maxEntries int, maxBytes int64) EvictionPolicy { return newLRU(maxEntries, maxBytes) }
+
+func newLRU(maxEntries int, maxBytes int64) *lru {
+	return &lru{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		ll:         list.New(),
+		elems:      make(map[string]*list.Element, maxEntries),
+	}
+}
+
+func (l *lru) Add(key string, size int) (victims []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, found := l.elems[key]; found {
+		e := elem.Value.(*lruEntry)
+		l.size += int64(size - e.size)
+		e.size = size
+		l.ll.MoveToFront(elem)
+	} else {
+		l.elems[key] = l.ll.PushFront(&lruEntry{key: key, size: size})
+		l.size += int64(size)
+	}
+
+	for l.overflow() {
+		elem := l.ll.Back()
+		e := elem.Value.(*lruEntry)
+		l.removeElement(elem)
+		victims = append(victims, e.key)
+	}
+
+	return victims
+}
+
+func (l *lru) overflow() bool {
+	return l.ll.Len() > 0 &&
+		((l.maxEntries > 0 && l.ll.Len() > l.maxEntries) || (l.maxBytes > 0 && l.size > l.maxBytes))
+}
+
+func (l *lru) Access(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, found := l.elems[key]; found {
+		l.ll.MoveToFront(elem)
+	}
+}
+
+func (l *lru) Remove(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, found := l.elems[key]; found {
+		l.removeElement(elem)
+	}
+}
+
+func (l *lru) Contains(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, found := l.elems[key]
+	return found
+}
+
+func (l *lru) removeElement(elem *list.Element) {
+	e := l.ll.Remove(elem).(*lruEntry)
+	delete(l.elems, e.key)
+	l.size -= int64(e.size)
+}
+
+func (l *lru) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.ll.Init()
+	clear(l.elems)
+	l.size = 0
+}
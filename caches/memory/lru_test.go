@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package memory
+
+import (
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestLRU(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.NotNil(NewLRU(3, 0))
+
+	l := newLRU(3, 0)
+	a.Empty(l.Add("k1", 1)).
+		Empty(l.Add("k2", 1)).
+		Empty(l.Add("k3", 1))
+
+	l.Access("k1")
+	a.Equal(l.Add("k4", 1), []string{"k2"}).
+		False(l.Contains("k2")).
+		True(l.Contains("k4"))
+	a.Equal(l.Add("k5", 1), []string{"k3"})
+
+	l.Remove("k1")
+	a.Empty(l.Add("k6", 1)).Equal(l.ll.Len(), 3)
+
+	l.Reset()
+	a.Equal(l.ll.Len(), 0).Zero(l.size).Empty(l.elems)
+
+	// maxBytes
+	l = newLRU(0, 10)
+	a.Empty(l.Add("k1", 4)).
+		Empty(l.Add("k2", 4)).
+		Equal(l.size, 8)
+	a.Equal(l.Add("k1", 8), []string{"k2"}).Equal(l.size, 8) // 更新 k1 的大小
+	a.Equal(l.Add("k3", 11), []string{"k1", "k3"}).Zero(l.size)
+}
@@ -1,4 +1,4 @@
-// SPDX-FileCopyrightText: 2017-2024 caixw
+// SPDX-FileCopyrightText: 2017-2026 caixw
 //
 // SPDX-License-Identifier: MIT
 
@@ -6,77 +6,324 @@
 package memory
 
 import (
+	"errors"
+	"hash/maphash"
+	"io"
+	"iter"
+	"reflect"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/issue9/cache"
 	"github.com/issue9/cache/caches"
 )
 
+// Driver 基于内存的缓存驱动
+//
+// 在 [cache.Driver] 的基础上提供了内存缓存特有的功能。
+type Driver interface {
+	cache.Driver
+	cache.BatchCache
+	cache.PrefixCleanable
+	cache.Scanner
+	cache.ItemCache
+	cache.ConditionalCache
+	cache.SignedCounterCache
+
+	// Stats 返回当前的统计信息
+	Stats() Stats
+
+	// Snapshot 将所有未过期的缓存项写入 w
+	//
+	// 写入的内容包括序列化之后的值以及过期时间，可以由 [Driver.Restore] 重新加载。
+	Snapshot(w io.Writer) error
+
+	// Restore 从 r 中加载由 [Driver.Snapshot] 写入的缓存项
+	//
+	// 已经过期的项会被忽略，同名的项会被覆盖。
+	Restore(r io.Reader) error
+}
+
+// Stats 内存缓存的统计信息
+type Stats struct {
+	Expired uint64 // 因过期而被回收的缓存项数量
+	Evicted uint64 // 因超出容量限制而被淘汰的缓存项数量
+}
+
 type memoryDriver struct {
-	items *sync.Map
+	shardCount int
+	shards     []*shard
+	mask       uint64
+	seed       maphash.Seed
+	expired    atomic.Uint64
+	evicted    atomic.Uint64
+
+	maxEntries int
+	maxBytes   int64
+	policy     EvictionPolicy // 为空表示不限制容量
+	copyMode   CopyMode       // 为零表示不启用对象模式
+	codec      cache.Codec
+
+	janitorInterval time.Duration
+	persistPath     string
+	persistInterval time.Duration
+	errlog          func(error)
+
+	stop      chan struct{} // 通知后台任务退出
+	tasks     sync.WaitGroup
+	closeOnce sync.Once
 }
 
+// 缓存项，一旦写入便不再修改，更新时需要替换整个对象。
 type item struct {
-	val    []byte
-	dur    time.Duration
-	expire time.Time // 过期的时间
+	val     []byte
+	obj     any // 对象模式下保存的值，不为空时 val 无效。
+	dur     time.Duration
+	expire  time.Time // 过期的时间
+	created time.Time
+	cas     uint64 // 版本标记，每次写入都会改变。
 }
 
+// 版本标记的来源，在进程内唯一。
+var casSeq atomic.Uint64
+
 // New 声明一个内存缓存
 //
-// [cache.Driver.Driver] 的返回类型为 [sync.Map]。
-func New() cache.Driver { return &memoryDriver{items: &sync.Map{}} }
+// 缓存项被分散在多个分片中，每个分片拥有独立的锁，分片的数量可由 [WithShards] 指定。
+//
+// [cache.Driver.Driver] 的返回值为 [Driver] 本身。
+func New(o ...Option) Driver {
+	d := &memoryDriver{seed: maphash.MakeSeed(), codec: caches.Default}
+	for _, opt := range o {
+		opt(d)
+	}
+	if d.policy == nil && (d.maxEntries > 0 || d.maxBytes > 0) {
+		d.policy = NewLRU(d.maxEntries, d.maxBytes)
+	}
+	d.initShards()
+
+	if d.janitorInterval > 0 {
+		d.every(d.janitorInterval, d.sweep)
+	}
+	d.startPersistence()
+
+	return d
+}
+
+func newItem(val []byte, ttl time.Duration) *item {
+	now := time.Now()
+	return &item{val: val, dur: ttl, expire: now.Add(ttl), created: now, cas: casSeq.Add(1)}
+}
+
+func (i *item) expired(now time.Time) bool { return i.dur > 0 && i.expire.Before(now) }
+
+// 返回一个过期时间为 ttl 的副本
+func (i *item) touch(ttl time.Duration) *item {
+	return &item{val: i.val, obj: i.obj, dur: ttl, expire: time.Now().Add(ttl), created: i.created, cas: i.cas}
+}
+
+// 返回元数据
+func (i *item) meta(now time.Time) *cache.Item {
+	var ttl time.Duration = cache.Forever
+	if i.dur > 0 {
+		ttl = max(i.expire.Sub(now), time.Nanosecond) // 避免与 Forever 混淆
+	}
+	return &cache.Item{TTL: ttl, OriginalTTL: i.dur, Created: i.created}
+}
+
+// 返回序列化之后的值，c 仅用于对象模式下的值。
+func (i *item) bytes(c cache.Codec) ([]byte, error) {
+	if i.obj != nil {
+		return c.Marshal(i.obj)
+	}
+	return i.val, nil
+}
 
 func (d *memoryDriver) Get(key string, v any) error {
-	if item, found := d.findItem(key); found {
-		return caches.Unmarshal(item.val, v)
+	d.shard(key).access(key)
+
+	item, found := d.findItem(key)
+	if !found {
+		return cache.ErrCacheMiss()
+	}
+	return d.decode(item, v)
+}
+
+// 将 i 的值解码至 v
+func (d *memoryDriver) decode(i *item, v any) error {
+	if i.obj != nil {
+		return d.fromObject(i.obj, v)
+	}
+	return d.codec.Unmarshal(i.val, v)
+}
+
+// 删除淘汰策略选中的缓存项
+//
+// 淘汰策略在 victims 返回时已经删除了对这些项的记录，之后又被记录的项不会被删除。
+func (d *memoryDriver) evict(victims []string) {
+	for _, k := range victims {
+		d.shard(k).evict(k)
 	}
-	return cache.ErrCacheMiss()
 }
 
 func (d *memoryDriver) findItem(key string) (*item, bool) {
-	i, found := d.items.Load(key)
+	s := d.shard(key)
+	i, found := s.load(key)
 	if !found {
 		return nil, false
 	}
 
-	ii := i.(*item)
-	if ii.dur > 0 && ii.expire.Before(time.Now()) {
-		d.items.Delete(key)
+	if i.expired(time.Now()) {
+		if s.compareAndDelete(key, i) {
+			d.expired.Add(1)
+		}
 		return nil, false
 	}
 
-	return ii, true
+	return i, true
 }
 
 func (d *memoryDriver) Set(key string, val any, ttl time.Duration) error {
+	i, size, err := d.makeItem(val, ttl)
+	if err != nil {
+		return err
+	}
+
+	d.evict(d.shard(key).store(key, i, size))
+	return nil
+}
+
+// 根据 val 生成缓存项，同时返回其占用的字节数。
+func (d *memoryDriver) makeItem(val any, ttl time.Duration) (*item, int, error) {
+	if d.copyMode > 0 {
+		if obj, ok := d.toObject(val); ok {
+			now := time.Now()
+			i := &item{obj: obj, dur: ttl, expire: now.Add(ttl), created: now, cas: casSeq.Add(1)}
+			return i, int(reflect.TypeOf(obj).Size()), nil
+		}
+	}
+
+	bs, err := d.codec.Marshal(val)
+	if err != nil {
+		return nil, 0, err
+	}
+	return newItem(bs, ttl), len(bs), nil
+}
+
+func (d *memoryDriver) Add(key string, val any, ttl time.Duration) error {
+	return d.storeIf(key, val, ttl, func(old *item) error {
+		if old != nil {
+			return cache.ErrNotStored()
+		}
+		return nil
+	})
+}
+
+func (d *memoryDriver) Replace(key string, val any, ttl time.Duration) error {
+	return d.storeIf(key, val, ttl, func(old *item) error {
+		if old == nil {
+			return cache.ErrNotStored()
+		}
+		return nil
+	})
+}
+
+func (d *memoryDriver) GetCAS(key string, v any) (uint64, error) {
+	d.shard(key).access(key)
+
 	i, found := d.findItem(key)
 	if !found {
-		bs, err := caches.Marshal(val)
-		if err != nil {
-			return err
+		return 0, cache.ErrCacheMiss()
+	}
+	return i.cas, d.decode(i, v)
+}
+
+func (d *memoryDriver) CompareAndSwap(key string, val any, token uint64, ttl time.Duration) error {
+	return d.storeIf(key, val, ttl, func(old *item) error {
+		switch {
+		case old == nil:
+			return cache.ErrCacheMiss()
+		case old.cas != token:
+			return cache.ErrNotStored()
+		default:
+			return nil
 		}
+	})
+}
 
-		d.items.Store(key, &item{
-			val:    bs,
-			dur:    ttl,
-			expire: time.Now().Add(ttl),
-		})
-		return nil
+func (d *memoryDriver) CompareAndDelete(key string, token uint64) error {
+	return d.shard(key).deleteIf(key, token)
+}
+
+// 在 cond 返回 nil 时写入 val
+func (d *memoryDriver) storeIf(key string, val any, ttl time.Duration, cond func(old *item) error) error {
+	i, size, err := d.makeItem(val, ttl)
+	if err != nil {
+		return err
 	}
 
-	bs, err := caches.Marshal(val)
-	if err == nil {
-		i.expire = time.Now().Add(i.dur)
-		i.val = bs
+	victims, err := d.shard(key).storeIf(key, i, size, cond)
+	if err != nil {
+		return err
 	}
-	return err
+	d.evict(victims)
+	return nil
 }
 
 func (d *memoryDriver) Delete(key string) error {
-	d.items.Delete(key)
+	d.shard(key).delete(key)
+	return nil
+}
+
+func (d *memoryDriver) GetItem(key string, v any) (*cache.Item, error) {
+	d.shard(key).access(key)
+
+	i, found := d.findItem(key)
+	if !found {
+		return nil, cache.ErrCacheMiss()
+	}
+
+	if err := d.decode(i, v); err != nil {
+		return nil, err
+	}
+	return i.meta(time.Now()), nil
+}
+
+func (d *memoryDriver) TTL(key string) (time.Duration, error) {
+	i, found := d.findItem(key)
+	if !found {
+		return 0, cache.ErrCacheMiss()
+	}
+	return i.meta(time.Now()).TTL, nil
+}
+
+func (d *memoryDriver) GetMulti(items map[string]any) error {
+	for key, v := range items {
+		switch err := d.Get(key, v); {
+		case errors.Is(err, cache.ErrCacheMiss()):
+			delete(items, key)
+		case err != nil:
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *memoryDriver) SetMulti(items map[string]any, ttl time.Duration) error {
+	for key, val := range items {
+		if err := d.Set(key, val, ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *memoryDriver) DeleteMulti(keys ...string) error {
+	for _, key := range keys {
+		d.shard(key).delete(key)
+	}
 	return nil
 }
 
@@ -86,77 +333,135 @@ func (d *memoryDriver) Exists(key string) bool {
 }
 
 func (d *memoryDriver) Clean() error {
-	d.items.Range(func(key, val any) bool {
-		d.items.Delete(key)
-		return true
-	})
+	// 同时锁定所有分片，避免在清除期间写入的项丢失在 policy 中的记录。
+	for _, s := range d.shards {
+		s.mu.Lock()
+	}
+	for _, s := range d.shards {
+		clear(s.items)
+	}
+	if d.policy != nil {
+		d.policy.Reset()
+	}
+	for _, s := range d.shards {
+		s.mu.Unlock()
+	}
 	return nil
 }
 
-func (d *memoryDriver) Close() error { return d.Clean() }
+func (d *memoryDriver) CleanPrefix(prefix string) error {
+	for _, s := range d.shards {
+		s.cleanPrefix(prefix)
+	}
+	return nil
+}
+
+// Keys 遍历所有与 pattern 匹配的键名
+//
+// 每次仅锁定一个分片，并在解锁之后再返回该分片中的键名。
+func (d *memoryDriver) Keys(pattern string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for _, s := range d.shards {
+			for _, k := range s.keys(pattern, time.Now()) {
+				if !yield(k, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (d *memoryDriver) Close() (err error) {
+	d.closeOnce.Do(func() {
+		d.stopTasks()
+		if d.persistPath != "" {
+			err = d.save()
+		}
+	})
+	return errors.Join(err, d.Clean())
+}
+
+func (d *memoryDriver) Stats() Stats {
+	return Stats{
+		Expired: d.expired.Load(),
+		Evicted: d.evicted.Load(),
+	}
+}
 
-func (d *memoryDriver) Driver() any { return d.items }
+func (d *memoryDriver) Driver() any { return d }
 
 func (d *memoryDriver) Ping() error { return nil }
 
 func (d *memoryDriver) Touch(key string, ttl time.Duration) error {
-	if i, found := d.findItem(key); found {
-		i.expire = time.Now().Add(i.dur)
+	s := d.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i, found := s.items[key]; found && !i.expired(time.Now()) {
+		s.items[key] = i.touch(ttl)
 	}
 	return nil
 }
 
 func (d *memoryDriver) Counter(key string, ttl time.Duration) (n uint64, f cache.SetCounterFunc, exist bool, err error) {
-	i, loaded := d.items.LoadOrStore(key, &item{
-		val:    []byte(strconv.FormatUint(0, 10)),
-		dur:    ttl,
-		expire: time.Now().Add(ttl),
-	})
-	if loaded {
+	s := d.shard(key)
+
+	var victims []string
+	s.mu.Lock()
+	if i, found := s.items[key]; found && !i.expired(time.Now()) {
 		exist = true
-		if n, err = strconv.ParseUint(string(i.(*item).val), 10, 64); err != nil {
-			return 0, nil, false, err
+		var bs []byte
+		if bs, err = i.bytes(caches.Default); err == nil {
+			n, err = strconv.ParseUint(string(bs), 10, 64)
 		}
 	} else {
-		n = 0
+		s.items[key] = newItem([]byte(strconv.FormatUint(0, 10)), ttl)
+		victims = s.added(key, 1)
 	}
+	s.mu.Unlock()
 
-	var locker sync.Mutex
+	if err != nil {
+		return 0, nil, false, err
+	}
+	d.evict(victims)
 
 	return n, func(n int) (uint64, error) {
-		locker.Lock()
-		defer locker.Unlock()
-
-		var num uint64
-		if i, found := d.items.Load(key); found {
-			num, err = strconv.ParseUint(string(i.(*item).val), 10, 64)
-			if err != nil {
-				return 0, err
-			}
-		} else {
-			return 0, cache.ErrCacheMiss()
-		}
+		num, victims, err := s.incr(key, n, ttl)
+		d.evict(victims)
+		return num, err
+	}, exist, nil
+}
 
-		switch {
-		case n == 0:
-			return num, nil
-		case n > 0:
-			num += uint64(n)
-		case n < 0:
-			n = -n
-			if uint64(n) >= num {
-				num = 0
-			} else {
-				num -= uint64(n)
-			}
+func (d *memoryDriver) SignedCounter(key string, ttl time.Duration, lower, upper int64) (n int64, f cache.SetSignedCounterFunc, exist bool, err error) {
+	if err = caches.CheckSignedRange(lower, upper); err != nil {
+		return 0, nil, false, err
+	}
+	s := d.shard(key)
+
+	var victims []string
+	s.mu.Lock()
+	if i, found := s.items[key]; found && !i.expired(time.Now()) {
+		exist = true
+		var bs []byte
+		if bs, err = i.bytes(caches.Default); err == nil {
+			n, err = strconv.ParseInt(string(bs), 10, 64)
 		}
+	} else {
+		n = caches.SignedZero(lower, upper)
+		bs := []byte(strconv.FormatInt(n, 10))
+		s.items[key] = newItem(bs, ttl)
+		victims = s.added(key, len(bs))
+	}
+	s.mu.Unlock()
 
-		d.items.Store(key, &item{
-			val:    []byte(strconv.FormatUint(num, 10)),
-			dur:    ttl,
-			expire: time.Now().Add(ttl),
-		})
+	if err != nil {
+		return 0, nil, false, err
+	}
+	d.evict(victims)
 
-		return num, nil
+	return n, func(n int64) (int64, error) {
+		num, victims, err := s.incrSigned(key, n, ttl, lower, upper)
+		d.evict(victims)
+		return num, err
 	}, exist, nil
 }
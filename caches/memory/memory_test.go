@@ -1,19 +1,31 @@
-// SPDX-FileCopyrightText: 2017-2024 caixw
+// SPDX-FileCopyrightText: 2017-2026 caixw
 //
 // SPDX-License-Identifier: MIT
 
 package memory
 
 import (
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/issue9/assert/v4"
 
 	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches"
 	"github.com/issue9/cache/cachetest"
 )
 
-var _ cache.Cache = &memoryDriver{}
+var (
+	_ cache.Cache              = &memoryDriver{}
+	_ cache.BatchCache         = &memoryDriver{}
+	_ cache.PrefixCleanable    = &memoryDriver{}
+	_ cache.Scanner            = &memoryDriver{}
+	_ cache.ItemCache          = &memoryDriver{}
+	_ cache.ConditionalCache   = &memoryDriver{}
+	_ cache.SignedCounterCache = &memoryDriver{}
+)
 
 func BenchmarkMemory(b *testing.B) {
 	a := assert.New(b, false)
@@ -25,6 +37,31 @@ func BenchmarkMemory(b *testing.B) {
 	cachetest.BenchObject(b, c)
 }
 
+func BenchmarkMemory_objectMode(b *testing.B) {
+	cachetest.BenchBasic(b, New(WithObjectMode(CopyOnWrite)))
+	cachetest.BenchObject(b, New(WithObjectMode(CopyOnWrite)))
+}
+
+func BenchmarkMemory_parallel(b *testing.B) {
+	b.Run("shards-1", func(b *testing.B) {
+		cachetest.BenchParallel(b, New(WithShards(1)))
+	})
+
+	b.Run("shards-default", func(b *testing.B) {
+		cachetest.BenchParallel(b, New())
+	})
+}
+
+func BenchmarkMemory_hitRatio(b *testing.B) {
+	b.Run("lru", func(b *testing.B) {
+		cachetest.BenchHitRatio(b, New(WithEvictionPolicy(NewLRU(1000, 0))))
+	})
+
+	b.Run("tinylfu", func(b *testing.B) {
+		cachetest.BenchHitRatio(b, New(WithEvictionPolicy(NewTinyLFU(1000, 0))))
+	})
+}
+
 func TestMemory(t *testing.T) {
 	a := assert.New(t, false)
 
@@ -34,6 +71,162 @@ func TestMemory(t *testing.T) {
 	cachetest.Basic(a, c)
 	cachetest.Object(a, c)
 	cachetest.Counter(a, c)
+	cachetest.Batch(a, c)
 
 	a.NotError(c.Close())
 }
+
+func TestMemory_limits(t *testing.T) {
+	a := assert.New(t, false)
+
+	c := New(WithMaxEntries(2), WithShards(1)) // 访问记录仅在同一分片内是及时的
+	a.NotError(c.Set("k1", 1, cache.Forever)).
+		NotError(c.Set("k2", 2, cache.Forever))
+	a.True(c.Exists("k1")) // k1 被访问，k2 成为最近最少使用的项
+	var v int
+	a.NotError(c.Get("k1", &v)).Equal(v, 1)
+	a.NotError(c.Set("k3", 3, cache.Forever))
+	a.True(c.Exists("k1")).
+		False(c.Exists("k2")).
+		True(c.Exists("k3")).
+		Equal(c.Stats().Evicted, 1)
+
+	a.NotError(c.Delete("k1")).
+		NotError(c.Set("k4", 4, cache.Forever)).
+		Equal(c.Stats().Evicted, 1)
+
+	c = New(WithMaxBytes(10))
+	for i := range 10 {
+		a.NotError(c.Set("k"+strconv.Itoa(i), "12345", cache.Forever))
+	}
+	a.Equal(c.Stats().Evicted, 8).
+		True(c.Exists("k8")).
+		True(c.Exists("k9")).
+		False(c.Exists("k7"))
+
+	a.NotError(c.Set("big", "12345678901", cache.Forever)).
+		False(c.Exists("big"))
+
+	a.NotError(c.Clean()).
+		NotError(c.Set("k1", "12345", cache.Forever)).
+		True(c.Exists("k1"))
+}
+
+func TestMemory_evict(t *testing.T) {
+	a := assert.New(t, false)
+
+	// k1 被选中淘汰之后，在删除之前又被再次写入。
+	d := New(WithMaxEntries(1)).(*memoryDriver)
+	a.NotError(d.Set("k1", 1, cache.Forever))
+	i, size, err := d.makeItem(2, cache.Forever)
+	a.NotError(err)
+	victims := d.shard("k2").store("k2", i, size)
+	a.Equal(victims, []string{"k1"})
+
+	a.NotError(d.Set("k1", 3, cache.Forever))
+	d.evict(victims)
+	var v int
+	a.NotError(d.Get("k1", &v)).Equal(v, 3).
+		False(d.Exists("k2"))
+
+	// 未被再次写入的依然会被删除
+	a.NotError(d.Set("k2", 2, cache.Forever)).
+		False(d.Exists("k1")).
+		True(d.Exists("k2"))
+}
+
+func TestMemory_concurrent(t *testing.T) {
+	a := assert.New(t, false)
+
+	c := New(WithMaxEntries(50))
+	n, set, _, err := c.Counter("n", cache.Forever)
+	a.NotError(err).Zero(n)
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				key := strconv.Itoa(j % 20)
+				a.NotError(c.Set(key, i, time.Minute))
+				a.NotError(c.Touch(key, time.Hour))
+				var v int
+				_ = c.Get(key, &v)
+				_, err := set(1)
+				a.NotError(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	a.True(c.Exists("n"))
+	n, err = set(0)
+	a.NotError(err).Equal(n, 1000)
+}
+
+func TestMemory_codec(t *testing.T) {
+	a := assert.New(t, false)
+
+	c := New(WithCodec(caches.JSON))
+	cachetest.Basic(a, c)
+	cachetest.Object(a, c)
+	cachetest.Counter(a, c)
+
+	a.NotError(c.Set("k1", "str", cache.Forever))
+	var raw []byte
+	a.NotError(caches.Default.Unmarshal(c.(*memoryDriver).shard("k1").items["k1"].val, &raw)).
+		Equal(string(raw), `"str"`)
+}
+
+func TestMemory_CleanPrefix(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := New(WithMaxEntries(10))
+	a.NotError(d.Set("p1:k1", 1, cache.Forever)).
+		NotError(d.Set("p1:k2", 2, cache.Forever)).
+		NotError(d.Set("p2:k1", 3, cache.Forever))
+
+	a.NotError(d.CleanPrefix("p1:"))
+	a.False(d.Exists("p1:k1")).
+		False(d.Exists("p1:k2")).
+		True(d.Exists("p2:k1"))
+
+	a.NotError(d.CleanPrefix(""))
+	a.False(d.Exists("p2:k1"))
+}
+
+func TestMemory_Keys(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := New()
+	cachetest.Keys(a, d)
+
+	// 过期的项
+	a.NotError(d.Set("expired", 1, time.Millisecond))
+	time.Sleep(10 * time.Millisecond)
+	for k := range d.Keys("expired") {
+		a.TB().Errorf("返回了已经过期的键名 %s", k)
+	}
+}
+
+func TestMemory_GetItem(t *testing.T) {
+	a := assert.New(t, false)
+
+	cachetest.Item(a, New(), true)
+	cachetest.Item(a, New(WithObjectMode(CopyOnWrite)), true)
+}
+
+func TestMemory_Conditional(t *testing.T) {
+	a := assert.New(t, false)
+
+	cachetest.Conditional(a, New())
+	cachetest.Conditional(a, New(WithObjectMode(CopyOnWrite)))
+}
+
+func TestMemory_SignedCounter(t *testing.T) {
+	a := assert.New(t, false)
+
+	cachetest.SignedCounter(a, New())
+	cachetest.SignedCounter(a, New(WithObjectMode(CopyOnWrite)))
+}
@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package memory
+
+import (
+	"encoding"
+	"encoding/gob"
+	"reflect"
+
+	"github.com/issue9/localeutil"
+)
+
+// CopyMode 对象模式下值的复制方式
+//
+// 无论哪种方式，都与 GOB 编码一样会忽略结构体的私有字段，
+// 但实现了 [gob.GobEncoder]、[encoding.BinaryMarshaler] 或 [encoding.TextMarshaler] 的类型会被整体复制。
+type CopyMode int8
+
+const (
+	// CopyOnWrite 仅复制最外层的值
+	//
+	// 指针、切片和 map 等引用类型的内容在缓存与调用方之间是共享的，
+	// 调用方在修改这些内容之前需要自行复制。
+	CopyOnWrite CopyMode = iota + 1
+
+	// DeepCopy 在写入和读取时都对值进行深度复制
+	//
+	// 值中不能包含循环引用。
+	DeepCopy
+)
+
+var (
+	gobEncoderType    = reflect.TypeFor[gob.GobEncoder]()
+	binaryMarshalType = reflect.TypeFor[encoding.BinaryMarshaler]()
+	textMarshalType   = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+var errInvalidTarget = localeutil.Error("the target must be a non-nil pointer")
+
+// 将 val 转换为对象模式下保存的值，指针会被解引用。
+//
+// 如果 val 无法以对象的形式保存，返回 false。
+func (d *memoryDriver) toObject(val any) (any, bool) {
+	v := reflect.ValueOf(val)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, false
+	}
+	return copyValue(v, d.copyMode == DeepCopy).Interface(), true
+}
+
+// 将对象模式下保存的 obj 写入 v
+func (d *memoryDriver) fromObject(obj any, v any) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return errInvalidTarget
+	}
+	elem := target.Elem()
+
+	src := copyValue(reflect.ValueOf(obj), d.copyMode == DeepCopy)
+	switch t := src.Type(); {
+	case t.AssignableTo(elem.Type()):
+		elem.Set(src)
+	case elem.Kind() == reflect.Pointer && t.AssignableTo(elem.Type().Elem()):
+		p := reflect.New(t)
+		p.Elem().Set(src)
+		elem.Set(p)
+	default: // 类型不同，比如将 int 读取到 string，与非对象模式保持一致。
+		bs, err := d.codec.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		return d.codec.Unmarshal(bs, v)
+	}
+	return nil
+}
+
+// 复制 v，仅复制结构体的公开字段，deep 表示是否复制引用类型的内容。
+func copyValue(v reflect.Value, deep bool) reflect.Value {
+	t := v.Type()
+	if wholeCopy(t) {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		nv := reflect.New(t).Elem()
+		for i := range t.NumField() {
+			if t.Field(i).IsExported() {
+				f := v.Field(i)
+				if deep {
+					f = copyValue(f, deep)
+				}
+				nv.Field(i).Set(f)
+			}
+		}
+		return nv
+	case reflect.Pointer:
+		if !deep || v.IsNil() {
+			return v
+		}
+		nv := reflect.New(t.Elem())
+		nv.Elem().Set(copyValue(v.Elem(), deep))
+		return nv
+	case reflect.Slice:
+		if !deep || v.IsNil() {
+			return v
+		}
+		nv := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := range v.Len() {
+			nv.Index(i).Set(copyValue(v.Index(i), deep))
+		}
+		return nv
+	case reflect.Array:
+		if !deep {
+			return v
+		}
+		nv := reflect.New(t).Elem()
+		for i := range v.Len() {
+			nv.Index(i).Set(copyValue(v.Index(i), deep))
+		}
+		return nv
+	case reflect.Map:
+		if !deep || v.IsNil() {
+			return v
+		}
+		nv := reflect.MakeMapWithSize(t, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			nv.SetMapIndex(copyValue(iter.Key(), deep), copyValue(iter.Value(), deep))
+		}
+		return nv
+	case reflect.Interface:
+		if !deep || v.IsNil() {
+			return v
+		}
+		nv := reflect.New(t).Elem()
+		nv.Set(copyValue(v.Elem(), deep))
+		return nv
+	default:
+		return v
+	}
+}
+
+// 类型 t 是否需要整体复制
+func wholeCopy(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	pt := reflect.PointerTo(t)
+	return pt.Implements(gobEncoderType) || pt.Implements(binaryMarshalType) || pt.Implements(textMarshalType)
+}
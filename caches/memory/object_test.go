@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package memory
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache"
+	"github.com/issue9/cache/cachetest"
+)
+
+type objectT struct {
+	Name  string
+	Tags  []string
+	Ptr   *objectT
+	Map   map[string]int
+	T     time.Time
+	Any   any
+	count int
+}
+
+func TestCopyValue(t *testing.T) {
+	a := assert.New(t, false)
+
+	now := time.Now()
+	src := objectT{
+		Name:  "n",
+		Tags:  []string{"t1"},
+		Ptr:   &objectT{Name: "p", count: 5},
+		Map:   map[string]int{"k": 1},
+		T:     now,
+		Any:   []int{1},
+		count: 5,
+	}
+
+	// 浅复制
+	v := copyValue(reflect.ValueOf(src), false).Interface().(objectT)
+	a.Equal(v.Name, "n").Zero(v.count).Equal(v.T, now).
+		Equal(v.Ptr, src.Ptr) // 共享指针
+	v.Tags[0] = "t2"
+	a.Equal(src.Tags[0], "t2")
+	src.Tags[0] = "t1"
+
+	// 深复制
+	v = copyValue(reflect.ValueOf(src), true).Interface().(objectT)
+	a.Equal(v.Name, "n").Zero(v.count).True(v.T.Equal(now)).
+		Equal(v.Ptr.Name, "p").Zero(v.Ptr.count)
+	v.Tags[0] = "t2"
+	v.Map["k"] = 2
+	v.Ptr.Name = "p2"
+	v.Any.([]int)[0] = 2
+	a.Equal(src.Tags[0], "t1").
+		Equal(src.Map["k"], 1).
+		Equal(src.Ptr.Name, "p").
+		Equal(src.Any, []int{1})
+}
+
+func TestMemory_objectMode(t *testing.T) {
+	a := assert.New(t, false)
+
+	for _, m := range []CopyMode{CopyOnWrite, DeepCopy} {
+		c := New(WithObjectMode(m))
+		cachetest.Basic(a, c)
+		cachetest.Object(a, c)
+		cachetest.Counter(a, c)
+
+		obj := &objectT{Name: "n", Tags: []string{"t1"}}
+		a.NotError(c.Set("obj", obj, cache.Forever))
+		obj.Name = "n2"
+
+		var v1 objectT
+		a.NotError(c.Get("obj", &v1)).Equal(v1.Name, "n")
+
+		var v2 *objectT
+		a.NotError(c.Get("obj", &v2)).Equal(v2.Name, "n")
+
+		var v3 any
+		a.NotError(c.Get("obj", &v3)).Equal(v3.(objectT).Name, "n")
+
+		a.ErrorIs(c.Get("obj", v1), errInvalidTarget)
+
+		// 不同类型之间的转换
+		a.NotError(c.Set("int", 5, cache.Forever))
+		var s string
+		a.NotError(c.Get("int", &s)).Equal(s, "5")
+
+		// 计数器
+		a.NotError(c.Set("n", uint64(5), cache.Forever))
+		n, set, found, err := c.Counter("n", cache.Forever)
+		a.NotError(err).True(found).Equal(n, 5)
+		n, err = set(1)
+		a.NotError(err).Equal(n, 6)
+
+		a.NotError(c.Close())
+	}
+
+	// 深复制
+	c := New(WithObjectMode(DeepCopy))
+	obj := &objectT{Name: "n", Tags: []string{"t1"}}
+	a.NotError(c.Set("obj", obj, cache.Forever))
+	obj.Tags[0] = "t2"
+	var v objectT
+	a.NotError(c.Get("obj", &v)).Equal(v.Tags, []string{"t1"})
+	v.Tags[0] = "t3"
+	a.NotError(c.Get("obj", &v)).Equal(v.Tags, []string{"t1"})
+}
@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package memory
+
+import (
+	"time"
+
+	"github.com/issue9/cache"
+)
+
+// Option 用于设置 [New] 的参数
+type Option func(*memoryDriver)
+
+// WithJanitor 启用后台清理过期缓存项的功能
+//
+// 默认情况下，过期的缓存项仅在访问时才会被回收，
+// 这会导致那些写入之后不再被访问的缓存项一直占用内存。
+// 启用此功能之后，每隔 interval 会遍历所有缓存项，回收已经过期的项。
+// 该功能会在 [cache.Driver.Close] 中被停止。
+//
+// interval 如果小于等于 0，表示不启用。
+func WithJanitor(interval time.Duration) Option {
+	return func(d *memoryDriver) { d.janitorInterval = interval }
+}
+
+// WithMaxEntries 限制缓存项的最大数量
+//
+// 超出数量时，会淘汰最近最少使用的缓存项，可以通过 [Stats.Evicted] 查看被淘汰的数量。
+// n 如果小于等于 0，表示不限制。如果指定了 [WithEvictionPolicy]，此选项将被忽略。
+func WithMaxEntries(n int) Option {
+	return func(d *memoryDriver) { d.maxEntries = n }
+}
+
+// WithMaxBytes 限制所有缓存项序列化之后的总字节数
+//
+// 超出限制时，会淘汰最近最少使用的缓存项，可以通过 [Stats.Evicted] 查看被淘汰的数量。
+// 单个缓存项如果超过此值，写入之后会被立即淘汰。
+// 启用 [WithObjectMode] 之后，缓存项的大小只是近似值。
+// n 如果小于等于 0，表示不限制。如果指定了 [WithEvictionPolicy]，此选项将被忽略。
+func WithMaxBytes(n int64) Option {
+	return func(d *memoryDriver) { d.maxBytes = n }
+}
+
+// WithEvictionPolicy 指定缓存项的淘汰策略
+//
+// 容量的限制由 p 自身决定，比如 [NewLRU] 和 [NewTinyLFU]。
+func WithEvictionPolicy(p EvictionPolicy) Option {
+	return func(d *memoryDriver) { d.policy = p }
+}
+
+// WithShards 指定分片的数量
+//
+// 分片数量越多，并发写入时锁的竞争越小。n 会被向上取 2 的幂，
+// 如果小于等于 0，则采用 CPU 数量的 4 倍。
+func WithShards(n int) Option {
+	return func(d *memoryDriver) { d.shardCount = n }
+}
+
+// WithObjectMode 启用对象模式
+//
+// 默认情况下，值在写入时会经过 [WithCodec] 指定的编码方式序列化，读取时再反序列化。
+// 启用对象模式之后，将直接保存 Go 对象，并在读取时通过反射赋值给目标，
+// 省去了序列化的开销。m 指定了写入和读取时值的复制方式。
+//
+// 对象模式下，[WithMaxBytes] 仅以值的类型大小计算，并不包含切片、字符串和指针等引用的内容，
+// 所以只能作为近似的限制。
+func WithObjectMode(m CopyMode) Option {
+	return func(d *memoryDriver) { d.copyMode = m }
+}
+
+// WithPersistence 将缓存内容持久化到文件
+//
+// 在 [New] 中会从 path 加载之前保存的内容，之后每隔 interval 以及在 [cache.Driver.Close]
+// 时将所有缓存项写入 path，以便重启之后的进程可以直接使用之前的缓存内容。
+// interval 小于等于 0 表示仅在关闭时保存。
+//
+// errlog 用于输出加载和定时保存时产生的错误，可以为空。
+func WithPersistence(path string, interval time.Duration, errlog func(error)) Option {
+	return func(d *memoryDriver) {
+		d.persistPath = path
+		d.persistInterval = interval
+		d.errlog = errlog
+	}
+}
+
+// WithCodec 指定缓存值的编码方式
+//
+// 默认为 [caches.Default]。
+func WithCodec(c cache.Codec) Option {
+	return func(d *memoryDriver) { d.codec = c }
+}
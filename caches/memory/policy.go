@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package memory
+
+// EvictionPolicy 缓存项的淘汰策略
+//
+// 策略仅记录缓存项的键名和大小，由驱动根据返回值删除实际的缓存项。
+// 实现者需要保证并发安全。
+type EvictionPolicy interface {
+	// Add 记录 key 被写入或是更新
+	//
+	// size 为缓存项序列化之后的字节数。
+	// 返回因超出容量而需要淘汰的缓存项，如果包含 key 本身，表示不接纳 key。
+	Add(key string, size int) (victims []string)
+
+	// Access 记录对 key 的一次访问
+	//
+	// 不论 key 是否存在都会调用此方法。
+	// 访问记录会先在各个分片中积累，再批量提交，所以调用时机会晚于实际的访问，
+	// 仅在同一分片内能保证与写入操作的先后顺序。
+	Access(key string)
+
+	// Remove 删除对 key 的记录
+	Remove(key string)
+
+	// Contains 是否存在对 key 的记录
+	//
+	// 驱动在删除 Add 返回的缓存项之前会以此确认其未被再次写入。
+	Contains(key string) bool
+
+	// Reset 清除所有的记录
+	Reset()
+}
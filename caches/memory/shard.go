@@ -0,0 +1,304 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package memory
+
+import (
+	"hash/maphash"
+	"math/bits"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches"
+)
+
+// 访问记录在分片中积累到此数量之后才会提交给淘汰策略
+const accessBatchSize = 64
+
+// 分片，每个分片拥有独立的锁。
+//
+// 对 policy 的 Add 和 Remove 操作均在持有 mu 时进行，以保证 items 与 policy 的记录一致；
+// 而访问记录则先保存在 accesses 中，再批量提交，以免每次读取都争用 policy 的锁。
+type shard struct {
+	mu    sync.RWMutex
+	items map[string]*item
+
+	policy  EvictionPolicy // 所有分片共用，为空表示不限制容量。
+	evicted *atomic.Uint64
+
+	accessMu sync.Mutex
+	accesses []string // 尚未提交给 policy 的访问记录
+}
+
+// 初始化分片，分片的数量会向上取 2 的幂。
+//
+// 未指定数量时，默认为 CPU 数量的 4 倍。
+func (d *memoryDriver) initShards() {
+	n := d.shardCount
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0) * 4
+	}
+	n = 1 << bits.Len(uint(n-1))
+
+	d.shards = make([]*shard, n)
+	for i := range d.shards {
+		d.shards[i] = &shard{items: make(map[string]*item), policy: d.policy, evicted: &d.evicted}
+	}
+	d.mask = uint64(n - 1)
+}
+
+func (d *memoryDriver) shard(key string) *shard {
+	return d.shards[maphash.String(d.seed, key)&d.mask]
+}
+
+func (s *shard) load(key string) (*item, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, found := s.items[key]
+	return i, found
+}
+
+// 记录对 key 的一次访问
+func (s *shard) access(key string) {
+	if s.policy == nil {
+		return
+	}
+
+	s.accessMu.Lock()
+	defer s.accessMu.Unlock()
+
+	if s.accesses = append(s.accesses, key); len(s.accesses) >= accessBatchSize {
+		s.flushAccesses()
+	}
+}
+
+// 将积累的访问记录提交给 policy，需要在持有 accessMu 时调用。
+func (s *shard) flushAccesses() {
+	for _, k := range s.accesses {
+		s.policy.Access(k)
+	}
+	s.accesses = s.accesses[:0]
+}
+
+// 记录 key 已经写入，需要在持有 mu 时调用。
+//
+// 如果 key 本身未被接纳，会直接删除，返回值为需要淘汰的其它缓存项。
+func (s *shard) added(key string, size int) (victims []string) {
+	if s.policy == nil {
+		return nil
+	}
+
+	// 先提交本分片的访问记录，使策略尽量以真实的访问顺序作出判断。
+	s.accessMu.Lock()
+	s.flushAccesses()
+	s.accessMu.Unlock()
+
+	for _, k := range s.policy.Add(key, size) {
+		if k == key {
+			delete(s.items, key)
+			s.evicted.Add(1)
+		} else {
+			victims = append(victims, k)
+		}
+	}
+	return victims
+}
+
+// 记录 key 已经被删除，需要在持有 mu 时调用。
+func (s *shard) removed(key string) {
+	if s.policy != nil {
+		s.policy.Remove(key)
+	}
+}
+
+// 删除被淘汰策略选中的 key
+//
+// 从被选中到获得锁的期间，key 可能已经被再次写入并重新记录在 policy 中，此时不能删除。
+func (s *shard) evict(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.items[key]; found && !s.policy.Contains(key) {
+		delete(s.items, key)
+		s.evicted.Add(1)
+	}
+}
+
+// 写入 i，size 为其占用的字节数，返回需要淘汰的其它缓存项。
+func (s *shard) store(key string, i *item, size int) (victims []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[key] = i
+	return s.added(key, size)
+}
+
+// 在 cond 返回 nil 时写入 i，cond 的参数为当前未过期的值，不存在时为空。
+func (s *shard) storeIf(key string, i *item, size int, cond func(*item) error) (victims []string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, found := s.items[key]
+	if found && old.expired(time.Now()) {
+		old = nil
+	}
+	if err := cond(old); err != nil {
+		return nil, err
+	}
+
+	s.items[key] = i
+	return s.added(key, size), nil
+}
+
+func (s *shard) incrSigned(key string, n int64, ttl time.Duration, lower, upper int64) (num int64, victims []string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, found := s.items[key]
+	if !found || i.expired(time.Now()) {
+		return 0, nil, cache.ErrCacheMiss()
+	}
+
+	bs, err := i.bytes(caches.Default)
+	if err != nil {
+		return 0, nil, err
+	}
+	if num, err = strconv.ParseInt(string(bs), 10, 64); err != nil {
+		return 0, nil, err
+	}
+
+	if n == 0 {
+		return num, nil, nil
+	}
+	if num, err = caches.AddSigned(num, n, lower, upper); err != nil {
+		return num, nil, err
+	}
+
+	bs = []byte(strconv.FormatInt(num, 10))
+	now := time.Now()
+	s.items[key] = &item{val: bs, dur: ttl, expire: now.Add(ttl), created: i.created, cas: casSeq.Add(1)}
+	return num, s.added(key, len(bs)), nil
+}
+
+// 仅在 key 对应的值的版本标记为 cas 时才删除
+func (s *shard) deleteIf(key string, cas uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch i, found := s.items[key]; {
+	case !found || i.expired(time.Now()):
+		return cache.ErrCacheMiss()
+	case i.cas != cas:
+		return cache.ErrNotStored()
+	default:
+		delete(s.items, key)
+		s.removed(key)
+		return nil
+	}
+}
+
+func (s *shard) delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+	s.removed(key)
+}
+
+// 仅在 key 对应的值依然为 i 时才删除
+func (s *shard) compareAndDelete(key string, i *item) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.items[key] == i {
+		delete(s.items, key)
+		s.removed(key)
+		return true
+	}
+	return false
+}
+
+// 删除所有键名以 prefix 开头的项
+func (s *shard) cleanPrefix(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k := range s.items {
+		if strings.HasPrefix(k, prefix) {
+			delete(s.items, k)
+			s.removed(k)
+		}
+	}
+}
+
+// 返回所有与 pattern 匹配且未过期的键名
+func (s *shard) keys(pattern string, now time.Time) (keys []string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for k, i := range s.items {
+		if !i.expired(now) && caches.Match(pattern, k) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// 删除所有在 now 之前过期的项，并返回被删除的数量。
+func (s *shard) sweep(now time.Time) (n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k, i := range s.items {
+		if i.expired(now) {
+			delete(s.items, k)
+			s.removed(k)
+			n++
+		}
+	}
+	return n
+}
+
+// 为计数器 key 增加 n，返回操作之后的值以及需要淘汰的其它缓存项。
+//
+// 数值最小为 0。
+func (s *shard) incr(key string, n int, ttl time.Duration) (num uint64, victims []string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, found := s.items[key]
+	if !found || i.expired(time.Now()) {
+		return 0, nil, cache.ErrCacheMiss()
+	}
+
+	bs, err := i.bytes(caches.Default)
+	if err != nil {
+		return 0, nil, err
+	}
+	if num, err = strconv.ParseUint(string(bs), 10, 64); err != nil {
+		return 0, nil, err
+	}
+
+	switch {
+	case n == 0:
+		return num, nil, nil
+	case n > 0:
+		num += uint64(n)
+	case n < 0:
+		n = -n
+		if uint64(n) >= num {
+			num = 0
+		} else {
+			num -= uint64(n)
+		}
+	}
+
+	bs = []byte(strconv.FormatUint(num, 10))
+	now := time.Now()
+	s.items[key] = &item{val: bs, dur: ttl, expire: now.Add(ttl), created: i.created, cas: casSeq.Add(1)}
+	return num, s.added(key, len(bs)), nil
+}
@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package memory
+
+import (
+	"encoding/gob"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/issue9/localeutil"
+
+	"github.com/issue9/cache"
+)
+
+const snapshotVersion = 1
+
+var errSnapshotVersion = localeutil.Error("unsupported snapshot version")
+
+type snapshotHeader struct {
+	Version int
+}
+
+type snapshotItem struct {
+	Key     string
+	Val     []byte
+	Dur     time.Duration
+	Expire  time.Time
+	Created time.Time
+}
+
+func (d *memoryDriver) Snapshot(w io.Writer) error {
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(&snapshotHeader{Version: snapshotVersion}); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, s := range d.shards {
+		if err := s.snapshot(enc, d.codec, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *shard) snapshot(enc *gob.Encoder, codec cache.Codec, now time.Time) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for k, i := range s.items {
+		if i.expired(now) {
+			continue
+		}
+
+		bs, err := i.bytes(codec)
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(&snapshotItem{Key: k, Val: bs, Dur: i.dur, Expire: i.expire, Created: i.created}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *memoryDriver) Restore(r io.Reader) error {
+	dec := gob.NewDecoder(r)
+
+	header := &snapshotHeader{}
+	if err := dec.Decode(header); err != nil {
+		return err
+	}
+	if header.Version != snapshotVersion {
+		return errSnapshotVersion
+	}
+
+	now := time.Now()
+	for {
+		si := &snapshotItem{}
+		if err := dec.Decode(si); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		i := &item{val: si.Val, dur: si.Dur, expire: si.Expire, created: si.Created, cas: casSeq.Add(1)}
+		if i.expired(now) {
+			continue
+		}
+		d.evict(d.shard(si.Key).store(si.Key, i, len(si.Val)))
+	}
+}
+
+// 从 persistPath 加载缓存内容并启动定时保存的任务
+func (d *memoryDriver) startPersistence() {
+	if d.persistPath == "" {
+		return
+	}
+
+	if err := d.load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		d.logError(err)
+	}
+
+	if d.persistInterval > 0 {
+		d.every(d.persistInterval, func(time.Time) {
+			if err := d.save(); err != nil {
+				d.logError(err)
+			}
+		})
+	}
+}
+
+func (d *memoryDriver) load() error {
+	f, err := os.Open(d.persistPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return d.Restore(f)
+}
+
+// 将缓存内容写入 persistPath
+//
+// 先写入临时文件再重命名，保证 persistPath 中始终是完整的内容。
+func (d *memoryDriver) save() error {
+	tmp := d.persistPath + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	if err := d.Snapshot(f); err != nil {
+		return errors.Join(err, f.Close(), os.Remove(tmp))
+	}
+	if err := f.Close(); err != nil {
+		return errors.Join(err, os.Remove(tmp))
+	}
+	return os.Rename(tmp, d.persistPath)
+}
+
+func (d *memoryDriver) logError(err error) {
+	if d.errlog != nil {
+		d.errlog(err)
+	}
+}
@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package memory
+
+import (
+	"bytes"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache"
+)
+
+func TestMemory_Snapshot(t *testing.T) {
+	a := assert.New(t, false)
+
+	c := New(WithObjectMode(CopyOnWrite))
+	a.NotError(c.Set("k1", 1, cache.Forever)).
+		NotError(c.Set("k2", "2", time.Hour)).
+		NotError(c.Set("k3", 3, 100*time.Millisecond)).
+		NotError(c.Set("k4", &objectT{Name: "4"}, cache.Forever))
+
+	buf := &bytes.Buffer{}
+	a.NotError(c.Snapshot(buf))
+	time.Sleep(200 * time.Millisecond) // k3 在加载时已经过期
+
+	c2 := New()
+	a.NotError(c2.Set("k1", 100, cache.Forever)) // 被覆盖
+	a.NotError(c2.Restore(buf))
+
+	var num int
+	a.NotError(c2.Get("k1", &num)).Equal(num, 1)
+	var str string
+	a.NotError(c2.Get("k2", &str)).Equal(str, "2")
+	a.False(c2.Exists("k3"))
+	var obj objectT
+	a.NotError(c2.Get("k4", &obj)).Equal(obj.Name, "4")
+
+	// 版本不兼容
+	buf.Reset()
+	a.NotError(gob.NewEncoder(buf).Encode(&snapshotHeader{Version: 100}))
+	a.ErrorIs(c2.Restore(buf), errSnapshotVersion)
+}
+
+func TestWithPersistence(t *testing.T) {
+	a := assert.New(t, false)
+
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	var errs []error
+	errlog := func(err error) { errs = append(errs, err) }
+
+	// 文件不存在
+	c := New(WithPersistence(path, 50*time.Millisecond, errlog))
+	a.Empty(errs)
+	a.NotError(c.Set("k1", 1, cache.Forever))
+	time.Sleep(200 * time.Millisecond)
+	_, err := os.Stat(path)
+	a.NotError(err) // 定时保存
+
+	a.NotError(c.Set("k2", 2, cache.Forever))
+	a.NotError(c.Close()).
+		False(c.Exists("k1"))
+
+	c = New(WithPersistence(path, 0, errlog))
+	a.Empty(errs).
+		True(c.Exists("k1")).
+		True(c.Exists("k2"))
+
+	// 无效的文件内容
+	a.NotError(os.WriteFile(path, []byte("invalid"), os.ModePerm))
+	New(WithPersistence(path, 0, errlog))
+	a.Length(errs, 1)
+}
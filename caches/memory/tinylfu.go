@@ -0,0 +1,280 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package memory
+
+import (
+	"container/list"
+	"hash/maphash"
+	"math/bits"
+	"sync"
+)
+
+const (
+	segWindow = iota
+	segProbation
+	segProtected
+)
+
+// 未指定 maxEntries 时频率统计的默认宽度
+const defaultSketchWidth = 4096
+
+// W-TinyLFU 的实现
+//
+// 新写入的项先进入 window 区（LRU），从 window 区淘汰的项作为候选者进入 probation 区，
+// 在容量不足时，与 probation 区的末尾项比较访问频率，频率低的被淘汰；
+// probation 区中再次被访问的项会被提升到 protected 区。
+//
+// 访问频率由 count-min sketch 统计，并定期减半，以适应访问模式的变化。
+type tinyLFU struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int64
+	size       int64
+
+	window    *list.List
+	probation *list.List
+	protected *list.List
+	elems     map[string]*list.Element
+	sketch    *sketch
+}
+
+type lfuEntry struct {
+	key  string
+	size int
+	seg  int
+}
+
+// NewTinyLFU 声明 W-TinyLFU 淘汰策略
+//
+// 相较于 [NewLRU]，在存在大量一次性访问（比如遍历）时依然能保持较高的命中率。
+// maxEntries 和 maxBytes 分别表示最大的缓存项数量和字节数，小于等于 0 表示不限制。
+func NewTinyLFU(maxEntries int, maxBytes int64) EvictionPolicy {
+	width := maxEntries
+	if width <= 0 {
+		width = defaultSketchWidth
+	}
+
+	return &tinyLFU{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		window:     list.New(),
+		probation:  list.New(),
+		protected:  list.New(),
+		elems:      make(map[string]*list.Element, max(maxEntries, 0)),
+		sketch:     newSketch(width),
+	}
+}
+
+// 返回 window 区和 protected 区的容量
+//
+// window 区占总容量的 1%，protected 区占剩余部分的 80%。
+func (l *tinyLFU) caps() (window, protected int) {
+	c := l.maxEntries
+	if c <= 0 {
+		c = len(l.elems)
+	}
+	window = max(1, c/100)
+	return window, (c - window) * 8 / 10
+}
+
+func (l *tinyLFU) list(seg int) *list.List {
+	switch seg {
+	case segWindow:
+		return l.window
+	case segProbation:
+		return l.probation
+	default:
+		return l.protected
+	}
+}
+
+func (l *tinyLFU) Add(key string, size int) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sketch.increment(key)
+
+	if elem, found := l.elems[key]; found {
+		e := elem.Value.(*lfuEntry)
+		l.size += int64(size - e.size)
+		e.size = size
+		l.list(e.seg).MoveToFront(elem)
+	} else {
+		l.elems[key] = l.window.PushFront(&lfuEntry{key: key, size: size, seg: segWindow})
+		l.size += int64(size)
+	}
+
+	windowCap, _ := l.caps()
+	for l.window.Len() > windowCap {
+		l.move(l.window.Back(), segProbation, true)
+	}
+
+	var victims []string
+	for l.overflow() {
+		victims = append(victims, l.evict())
+	}
+	return victims
+}
+
+func (l *tinyLFU) overflow() bool {
+	return len(l.elems) > 0 &&
+		((l.maxEntries > 0 && len(l.elems) > l.maxEntries) || (l.maxBytes > 0 && l.size > l.maxBytes))
+}
+
+// 淘汰一项并返回其键名
+func (l *tinyLFU) evict() string {
+	for l.probation.Len() < 2 && l.protected.Len() > 0 {
+		l.move(l.protected.Back(), segProbation, false)
+	}
+
+	var elem *list.Element
+	switch {
+	case l.probation.Len() >= 2: // 候选者与末尾项比较访问频率
+		candidate, victim := l.probation.Front(), l.probation.Back()
+		if l.sketch.estimate(candidate.Value.(*lfuEntry).key) > l.sketch.estimate(victim.Value.(*lfuEntry).key) {
+			elem = victim
+		} else {
+			elem = candidate
+		}
+	case l.probation.Len() == 1:
+		elem = l.probation.Back()
+	default:
+		elem = l.window.Back()
+	}
+
+	e := elem.Value.(*lfuEntry)
+	l.remove(elem)
+	return e.key
+}
+
+// 将 elem 移至 seg 区，front 表示是否放在头部。
+func (l *tinyLFU) move(elem *list.Element, seg int, front bool) {
+	e := l.list(elem.Value.(*lfuEntry).seg).Remove(elem).(*lfuEntry)
+	e.seg = seg
+	if front {
+		l.elems[e.key] = l.list(seg).PushFront(e)
+	} else {
+		l.elems[e.key] = l.list(seg).PushBack(e)
+	}
+}
+
+func (l *tinyLFU) Access(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sketch.increment(key)
+
+	elem, found := l.elems[key]
+	if !found {
+		return
+	}
+
+	switch e := elem.Value.(*lfuEntry); e.seg {
+	case segProbation:
+		l.move(elem, segProtected, true)
+		_, protectedCap := l.caps()
+		for l.protected.Len() > max(protectedCap, 1) {
+			l.move(l.protected.Back(), segProbation, true)
+		}
+	default:
+		l.list(e.seg).MoveToFront(elem)
+	}
+}
+
+func (l *tinyLFU) Remove(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, found := l.elems[key]; found {
+		l.remove(elem)
+	}
+}
+
+func (l *tinyLFU) Contains(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, found := l.elems[key]
+	return found
+}
+
+func (l *tinyLFU) remove(elem *list.Element) {
+	e := l.list(elem.Value.(*lfuEntry).seg).Remove(elem).(*lfuEntry)
+	delete(l.elems, e.key)
+	l.size -= int64(e.size)
+}
+
+func (l *tinyLFU) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.window.Init()
+	l.probation.Init()
+	l.protected.Init()
+	clear(l.elems)
+	l.size = 0
+	l.sketch.reset()
+}
+
+// 4 位计数器的 count-min sketch
+type sketch struct {
+	rows      [4][]uint8
+	mask      uint32
+	seed      maphash.Seed
+	additions int
+	sampleAt  int // 累计增加次数达到此值时，所有计数器减半。
+}
+
+func newSketch(width int) *sketch {
+	width = 1 << bits.Len(uint(max(width, 16)-1)) // 向上取 2 的幂
+	s := &sketch{
+		mask:     uint32(width - 1),
+		seed:     maphash.MakeSeed(),
+		sampleAt: 10 * width,
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+func (s *sketch) indexes(key string) (h1, h2 uint32) {
+	h := maphash.String(s.seed, key)
+	return uint32(h), uint32(h >> 32)
+}
+
+func (s *sketch) increment(key string) {
+	h1, h2 := s.indexes(key)
+	for i := range s.rows {
+		idx := (h1 + uint32(i)*h2) & s.mask
+		if s.rows[i][idx] < 15 {
+			s.rows[i][idx]++
+		}
+	}
+
+	if s.additions++; s.additions >= s.sampleAt {
+		for i := range s.rows {
+			for j := range s.rows[i] {
+				s.rows[i][j] >>= 1
+			}
+		}
+		s.additions /= 2
+	}
+}
+
+func (s *sketch) estimate(key string) uint8 {
+	h1, h2 := s.indexes(key)
+	n := uint8(15)
+	for i := range s.rows {
+		n = min(n, s.rows[i][(h1+uint32(i)*h2)&s.mask])
+	}
+	return n
+}
+
+func (s *sketch) reset() {
+	for i := range s.rows {
+		clear(s.rows[i])
+	}
+	s.additions = 0
+}
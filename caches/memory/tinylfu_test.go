@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package memory
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache"
+)
+
+func TestTinyLFU(t *testing.T) {
+	a := assert.New(t, false)
+
+	l := NewTinyLFU(3, 0).(*tinyLFU)
+	a.Empty(l.Add("k1", 1)).
+		Empty(l.Add("k2", 1)).
+		Empty(l.Add("k3", 1))
+
+	// 提高 k1 和 k2 的访问频率
+	for range 5 {
+		l.Access("k1")
+		l.Access("k2")
+	}
+
+	// 从 window 区移出的低频项 k3 不会被接纳
+	a.Equal(l.Add("k4", 1), []string{"k3"}).
+		Length(l.elems, 3).
+		False(l.Contains("k3"))
+
+	// 访问频率超过已有项之后可以被接纳
+	for range 10 {
+		l.Access("k4")
+	}
+	victims := l.Add("k5", 1)
+	a.Length(victims, 1).NotEqual(victims[0], "k4")
+	_, found := l.elems["k4"]
+	a.True(found)
+
+	l.Remove("k4")
+	a.Length(l.elems, 2)
+
+	l.Reset()
+	a.Empty(l.elems).Zero(l.size).
+		Zero(l.window.Len()).Zero(l.probation.Len()).Zero(l.protected.Len()).
+		Zero(l.sketch.estimate("k1"))
+
+	// maxBytes
+	l = NewTinyLFU(0, 10).(*tinyLFU)
+	a.Empty(l.Add("k1", 4)).
+		Empty(l.Add("k2", 4)).
+		Equal(l.size, 8)
+	a.Equal(l.Add("k3", 11), []string{"k2", "k1", "k3"}).Zero(l.size)
+}
+
+func TestSketch(t *testing.T) {
+	a := assert.New(t, false)
+
+	s := newSketch(10)
+	a.Equal(s.mask, 15).Equal(s.sampleAt, 160)
+
+	for range 20 {
+		s.increment("k1")
+	}
+	a.Equal(s.estimate("k1"), 15) // 最大值为 15
+
+	s.increment("k2")
+	a.True(s.estimate("k2") >= 1)
+
+	for i := range 200 {
+		s.increment(strconv.Itoa(i))
+	}
+	a.True(s.estimate("k1") < 15) // 已经被减半
+}
+
+func TestMemory_tinyLFU(t *testing.T) {
+	a := assert.New(t, false)
+
+	c := New(WithEvictionPolicy(NewTinyLFU(10, 0)))
+	for i := range 20 {
+		a.NotError(c.Set(strconv.Itoa(i), i, cache.Forever))
+	}
+	a.Equal(c.Stats().Evicted, 10)
+}
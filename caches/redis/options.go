@@ -0,0 +1,17 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package redis
+
+import "github.com/issue9/cache"
+
+// Option 用于设置 [New] 和 [NewFromURL] 的参数
+type Option func(*redisDriver)
+
+// WithCodec 指定缓存值的编码方式
+//
+// 默认为 [caches.Default]。
+func WithCodec(c cache.Codec) Option {
+	return func(d *redisDriver) { d.codec = c }
+}
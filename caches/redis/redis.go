@@ -1,4 +1,4 @@
-// SPDX-FileCopyrightText: 2017-2024 caixw
+// SPDX-FileCopyrightText: 2017-2026 caixw
 //
 // SPDX-License-Identifier: MIT
 
@@ -7,7 +7,12 @@ package redis
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"iter"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -19,6 +24,10 @@ import (
 type redisDriver struct {
 	client       *redis.Client
 	decrByScript *redis.Script
+	casScript    *redis.Script
+	cadScript    *redis.Script
+	incrScript   *redis.Script
+	codec        cache.Codec
 }
 
 // redis 处理 DECRBY 的事务脚本
@@ -30,6 +39,73 @@ end
 return (cnt < 0 and 0 or cnt)
 `
 
+// redis 处理 CompareAndSwap 的事务脚本
+//
+// 版本标记为值的 SHA1 的前 16 位，参数依次为版本标记、新值和以毫秒表示的 ttl。
+const redisCASScript = `
+local v = redis.call('GET', KEYS[1])
+if not v then
+    return -1
+end
+if string.sub(redis.sha1hex(v), 1, 16) ~= ARGV[1] then
+    return 0
+end
+if tonumber(ARGV[3]) > 0 then
+    redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+else
+    redis.call('SET', KEYS[1], ARGV[2])
+end
+return 1
+`
+
+// redis 处理 CompareAndDelete 的事务脚本
+//
+// 版本标记与 redisCASScript 相同。
+const redisCADScript = `
+local v = redis.call('GET', KEYS[1])
+if not v then
+    return -1
+end
+if string.sub(redis.sha1hex(v), 1, 16) ~= ARGV[1] then
+    return 0
+end
+redis.call('DEL', KEYS[1])
+return 1
+`
+
+// redis 处理有符号计数器的事务脚本
+//
+// 参数依次为增加的数值、下限、上限和以毫秒表示的 ttl，
+// 返回是否成功以及操作之后的值，值以字符串返回以避免 Lua 的数值精度问题，
+// 不存在时返回 -1，超出范围或是溢出时返回 0 以及原来的值。
+// 范围的判断由 Lua 的浮点数进行，所以在 2^53 之外的边界可能会有偏差。
+const redisIncrByScript = `
+if redis.call('EXISTS', KEYS[1]) == 0 then
+    return {-1, ''}
+end
+
+local cnt = redis.pcall('INCRBY', KEYS[1], ARGV[1])
+if type(cnt) == 'table' and cnt.err then
+    if string.find(cnt.err, 'overflow') then
+        return {0, redis.call('GET', KEYS[1])}
+    end
+    return cnt
+end
+
+if cnt < tonumber(ARGV[2]) or cnt > tonumber(ARGV[3]) then
+    redis.call('DECRBY', KEYS[1], ARGV[1])
+    return {0, redis.call('GET', KEYS[1])}
+end
+
+if tonumber(ARGV[4]) > 0 then
+    redis.call('PEXPIRE', KEYS[1], ARGV[4])
+end
+return {1, redis.call('GET', KEYS[1])}
+`
+
+// SCAN 每次返回的键名数量
+const scanCount = 1000
+
 // NewFromURL 声明基于 [redis] 的缓存系统
 //
 // url 为符合 [Redis URI scheme] 的字符串。
@@ -37,66 +113,326 @@ return (cnt < 0 and 0 or cnt)
 //
 // [Redis URI scheme]: https://www.iana.org/assignments/uri-schemes/prov/redis
 // [redis]: https://redis.io/
-func NewFromURL(url string) (cache.Driver, error) {
+func NewFromURL(url string, o ...Option) (cache.Driver, error) {
 	opt, err := redis.ParseURL(url)
 	if err != nil {
 		return nil, err
 	}
-	return New(redis.NewClient(opt)), nil
+	return New(redis.NewClient(opt), o...), nil
 }
 
 // New 声明基于 redis 的缓存系统
-func New(c *redis.Client) cache.Driver {
-	return &redisDriver{
+//
+// 返回对象同时实现了 [cache.ContextCache]，ctx 会传递给 [redis.Client]；
+// 以及 [cache.BatchCache]，分别采用 MGET、PIPELINE 和 DEL 实现；
+// 以及 [cache.ConditionalCache]，其中 CompareAndSwap 由 Lua 脚本实现。
+func New(c *redis.Client, o ...Option) cache.Driver {
+	d := &redisDriver{
 		client:       c,
 		decrByScript: redis.NewScript(redisDecrByScript),
+		casScript:    redis.NewScript(redisCASScript),
+		cadScript:    redis.NewScript(redisCADScript),
+		incrScript:   redis.NewScript(redisIncrByScript),
+		codec:        caches.Default,
+	}
+	for _, opt := range o {
+		opt(d)
 	}
+	return d
 }
 
 func (d *redisDriver) Get(key string, val any) error {
-	bs, err := d.client.Get(context.Background(), key).Bytes()
+	return d.GetContext(context.Background(), key, val)
+}
+
+func (d *redisDriver) GetContext(ctx context.Context, key string, val any) error {
+	bs, err := d.client.Get(ctx, key).Bytes()
 	if errors.Is(err, redis.Nil) {
 		return cache.ErrCacheMiss()
 	} else if err != nil {
 		return err
 	}
 
-	return caches.Unmarshal(bs, val)
+	return d.codec.Unmarshal(bs, val)
 }
 
 func (d *redisDriver) Set(key string, val any, ttl time.Duration) error {
-	bs, err := caches.Marshal(val)
+	return d.SetContext(context.Background(), key, val, ttl)
+}
+
+func (d *redisDriver) SetContext(ctx context.Context, key string, val any, ttl time.Duration) error {
+	bs, err := d.codec.Marshal(val)
+	if err != nil {
+		return err
+	}
+	return d.client.Set(ctx, key, bs, ttl).Err()
+}
+
+func (d *redisDriver) Add(key string, val any, ttl time.Duration) error {
+	bs, err := d.codec.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	ok, err := d.client.SetNX(context.Background(), key, bs, ttl).Result()
+	if err == nil && !ok {
+		err = cache.ErrNotStored()
+	}
+	return err
+}
+
+func (d *redisDriver) Replace(key string, val any, ttl time.Duration) error {
+	bs, err := d.codec.Marshal(val)
 	if err != nil {
 		return err
 	}
-	return d.client.Set(context.Background(), key, bs, ttl).Err()
+
+	ok, err := d.client.SetXX(context.Background(), key, bs, ttl).Result()
+	if err == nil && !ok {
+		err = cache.ErrNotStored()
+	}
+	return err
+}
+
+// GetCAS 获取缓存项的值以及当前的版本标记
+//
+// 版本标记由值的 SHA1 计算而来，所以写入相同的值并不会改变版本标记。
+func (d *redisDriver) GetCAS(key string, v any) (uint64, error) {
+	bs, err := d.client.Get(context.Background(), key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return 0, cache.ErrCacheMiss()
+	} else if err != nil {
+		return 0, err
+	}
+
+	sum := sha1.Sum(bs)
+	return binary.BigEndian.Uint64(sum[:8]), d.codec.Unmarshal(bs, v)
+}
+
+func (d *redisDriver) CompareAndSwap(key string, val any, token uint64, ttl time.Duration) error {
+	bs, err := d.codec.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	args := []any{casToken(token), bs, milliseconds(ttl)}
+	return casResult(d.casScript.Run(context.Background(), d.client, []string{key}, args...).Int())
+}
+
+// 将 ttl 转换为脚本中以毫秒表示的过期时间
+//
+// 不足 1 毫秒的 ttl 按 1 毫秒计算，以免被当作永不过期，与 [redis.Client.Set] 的处理方式相同。
+func milliseconds(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return max(ttl.Milliseconds(), 1)
 }
 
-func (d *redisDriver) Delete(key string) error { return d.client.Del(context.Background(), key).Err() }
+func (d *redisDriver) CompareAndDelete(key string, token uint64) error {
+	return casResult(d.cadScript.Run(context.Background(), d.client, []string{key}, casToken(token)).Int())
+}
+
+// 将版本标记转换为脚本中的格式
+func casToken(token uint64) string { return fmt.Sprintf("%016x", token) }
+
+// 将脚本的返回值转换为错误
+func casResult(rslt int, err error) error {
+	switch {
+	case err != nil:
+		return err
+	case rslt < 0:
+		return cache.ErrCacheMiss()
+	case rslt == 0:
+		return cache.ErrNotStored()
+	default:
+		return nil
+	}
+}
+
+func (d *redisDriver) Delete(key string) error { return d.DeleteContext(context.Background(), key) }
+
+func (d *redisDriver) DeleteContext(ctx context.Context, key string) error {
+	return d.client.Del(ctx, key).Err()
+}
+
+// GetItem 获取缓存项的值以及元数据
+//
+// redis 仅能提供剩余的有效时间，[cache.Item.OriginalTTL] 始终为 -1，[cache.Item.Created] 始终为零值。
+func (d *redisDriver) GetItem(key string, v any) (*cache.Item, error) {
+	ctx := context.Background()
+	pipe := d.client.Pipeline()
+	get := pipe.Get(ctx, key)
+	pttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); errors.Is(err, redis.Nil) {
+		return nil, cache.ErrCacheMiss()
+	} else if err != nil {
+		return nil, err
+	}
+
+	bs, err := get.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	if err := d.codec.Unmarshal(bs, v); err != nil {
+		return nil, err
+	}
 
-func (d *redisDriver) Exists(key string) bool {
-	rslt, err := d.client.Exists(context.Background(), key).Result()
+	return &cache.Item{TTL: pttlToTTL(pttl.Val()), OriginalTTL: -1}, nil
+}
+
+func (d *redisDriver) TTL(key string) (time.Duration, error) {
+	ttl, err := d.client.PTTL(context.Background(), key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl == -2 {
+		return 0, cache.ErrCacheMiss()
+	}
+	return pttlToTTL(ttl), nil
+}
+
+// 将 PTTL 的返回值转换为 [cache.Item.TTL]
+func pttlToTTL(ttl time.Duration) time.Duration {
+	if ttl == -1 { // 未设置过期时间
+		return cache.Forever
+	}
+	return max(ttl, time.Millisecond)
+}
+
+func (d *redisDriver) GetMulti(items map[string]any) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+
+	vals, err := d.client.MGet(context.Background(), keys...).Result()
+	if err != nil {
+		return err
+	}
+
+	for i, key := range keys {
+		s, ok := vals[i].(string)
+		if !ok { // 不存在的值为 nil
+			delete(items, key)
+			continue
+		}
+
+		if err := d.codec.Unmarshal([]byte(s), items[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *redisDriver) SetMulti(items map[string]any, ttl time.Duration) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	pipe := d.client.Pipeline()
+	for key, val := range items {
+		bs, err := d.codec.Marshal(val)
+		if err != nil {
+			return err
+		}
+		pipe.Set(ctx, key, bs, ttl)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (d *redisDriver) DeleteMulti(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return d.client.Del(context.Background(), keys...).Err()
+}
+
+func (d *redisDriver) Exists(key string) bool { return d.ExistsContext(context.Background(), key) }
+
+func (d *redisDriver) ExistsContext(ctx context.Context, key string) bool {
+	rslt, err := d.client.Exists(ctx, key).Result()
 	return err == nil && rslt > 0
 }
 
 func (d *redisDriver) Clean() error { return d.client.FlushDB(context.Background()).Err() }
 
+// CleanPrefix 清除所有键名以 prefix 开头的缓存项
+//
+// 采用 SCAN 分批查找键名并以 UNLINK 删除，不会长时间阻塞服务器，
+// 但是在此期间写入的缓存项不一定会被删除。
+func (d *redisDriver) CleanPrefix(prefix string) error {
+	ctx := context.Background()
+	iter := d.client.Scan(ctx, 0, cache.EscapePattern(prefix)+"*", scanCount).Iterator()
+
+	keys := make([]string, 0, scanCount)
+	for iter.Next(ctx) {
+		if keys = append(keys, iter.Val()); len(keys) >= scanCount {
+			if err := d.client.Unlink(ctx, keys...).Err(); err != nil {
+				return err
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	if len(keys) > 0 {
+		return d.client.Unlink(ctx, keys...).Err()
+	}
+	return nil
+}
+
+// Keys 遍历所有与 pattern 匹配的键名
+//
+// 采用 SCAN 命令实现，同一键名可能会被返回多次。
+func (d *redisDriver) Keys(pattern string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		ctx := context.Background()
+		it := d.client.Scan(ctx, 0, pattern, scanCount).Iterator()
+		for it.Next(ctx) {
+			if !yield(it.Val(), nil) {
+				return
+			}
+		}
+
+		if err := it.Err(); err != nil {
+			yield("", err)
+		}
+	}
+}
+
 func (d *redisDriver) Close() error { return d.client.Close() }
 
 func (d *redisDriver) Driver() any { return d.client }
 
 func (d *redisDriver) Ping() error { return d.client.Ping(context.Background()).Err() }
 
-func (d *redisDriver) Touch(key string, ttl time.Duration) (err error) {
-	if _, err = d.client.Expire(context.Background(), key, ttl).Result(); errors.Is(err, redis.Nil) {
+func (d *redisDriver) Touch(key string, ttl time.Duration) error {
+	return d.TouchContext(context.Background(), key, ttl)
+}
+
+func (d *redisDriver) TouchContext(ctx context.Context, key string, ttl time.Duration) (err error) {
+	if _, err = d.client.Expire(ctx, key, ttl).Result(); errors.Is(err, redis.Nil) {
 		err = nil
 	}
 	return err
 }
 
-func (d *redisDriver) Counter(key string, ttl time.Duration) (n uint64, f cache.SetCounterFunc, exist bool, err error) {
-	if n, err = cache.Get[uint64](d, key); errors.Is(err, cache.ErrCacheMiss()) {
-		err = d.Set(key, 0, ttl)
+func (d *redisDriver) Counter(key string, ttl time.Duration) (uint64, cache.SetCounterFunc, bool, error) {
+	return d.CounterContext(context.Background(), key, ttl)
+}
+
+func (d *redisDriver) CounterContext(ctx context.Context, key string, ttl time.Duration) (n uint64, f cache.SetCounterFunc, exist bool, err error) {
+	if n, err = d.getCounter(ctx, key); errors.Is(err, cache.ErrCacheMiss()) {
+		err = d.client.Set(ctx, key, 0, ttl).Err()
 		n = 0
 	} else {
 		exist = true
@@ -108,27 +444,85 @@ func (d *redisDriver) Counter(key string, ttl time.Duration) (n uint64, f cache.
 	return n, func(n int) (uint64, error) {
 		switch {
 		default: // n==0
-			return cache.Get[uint64](d, key)
+			return d.getCounter(ctx, key)
 		case n > 0:
-			if !d.Exists(key) {
+			if !d.ExistsContext(ctx, key) {
 				return 0, cache.ErrCacheMiss()
 			}
 
-			rslt, err := d.client.IncrBy(context.Background(), key, int64(n)).Result()
+			rslt, err := d.client.IncrBy(ctx, key, int64(n)).Result()
 			if err == nil && ttl > 0 {
-				_, err = d.client.Expire(context.Background(), key, ttl).Result()
+				_, err = d.client.Expire(ctx, key, ttl).Result()
 			}
 			return uint64(rslt), err
 		case n < 0:
-			if !d.Exists(key) {
+			if !d.ExistsContext(ctx, key) {
 				return 0, cache.ErrCacheMiss()
 			}
 
-			rslt, err := d.decrByScript.Run(context.Background(), d.client, []string{key}, int64(-n)).Int64()
+			rslt, err := d.decrByScript.Run(ctx, d.client, []string{key}, int64(-n)).Int64()
 			if err == nil && ttl > 0 {
-				_, err = d.client.Expire(context.Background(), key, ttl).Result()
+				_, err = d.client.Expire(ctx, key, ttl).Result()
 			}
 			return uint64(rslt), err
 		}
 	}, exist, nil
 }
+
+func (d *redisDriver) SignedCounter(key string, ttl time.Duration, lower, upper int64) (n int64, f cache.SetSignedCounterFunc, exist bool, err error) {
+	if err = caches.CheckSignedRange(lower, upper); err != nil {
+		return 0, nil, false, err
+	}
+	ctx := context.Background()
+	if n, err = d.client.Get(ctx, key).Int64(); errors.Is(err, redis.Nil) {
+		n = caches.SignedZero(lower, upper)
+		var added bool
+		if added, err = d.client.SetNX(ctx, key, n, ttl).Result(); err == nil && !added { // 已被其它进程初始化
+			n, err = d.client.Get(ctx, key).Int64()
+			exist = true
+		}
+	} else {
+		exist = true
+	}
+	if err != nil {
+		return 0, nil, false, err
+	}
+
+	return n, func(n int64) (int64, error) {
+		if n == 0 {
+			v, err := d.client.Get(ctx, key).Int64()
+			if errors.Is(err, redis.Nil) {
+				return 0, cache.ErrCacheMiss()
+			}
+			return v, err
+		}
+
+		args := []any{n, lower, upper, milliseconds(ttl)}
+		rslt, err := d.incrScript.Run(ctx, d.client, []string{key}, args...).Slice()
+		if err != nil {
+			return 0, err
+		}
+
+		switch rslt[0].(int64) {
+		case -1:
+			return 0, cache.ErrCacheMiss()
+		case 0:
+			v, err := strconv.ParseInt(rslt[1].(string), 10, 64)
+			if err != nil {
+				return 0, err
+			}
+			return v, cache.ErrOutOfRange()
+		default:
+			return strconv.ParseInt(rslt[1].(string), 10, 64)
+		}
+	}, exist, nil
+}
+
+// 获取计数器的值
+func (d *redisDriver) getCounter(ctx context.Context, key string) (uint64, error) {
+	n, err := d.client.Get(ctx, key).Uint64()
+	if errors.Is(err, redis.Nil) {
+		return 0, cache.ErrCacheMiss()
+	}
+	return n, err
+}
@@ -1,4 +1,4 @@
-// SPDX-FileCopyrightText: 2017-2024 caixw
+// SPDX-FileCopyrightText: 2017-2026 caixw
 //
 // SPDX-License-Identifier: MIT
 
@@ -6,14 +6,25 @@ package redis
 
 import (
 	"testing"
+	"time"
 
 	"github.com/issue9/assert/v4"
 
 	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches"
 	"github.com/issue9/cache/cachetest"
 )
 
-var _ cache.Cache = &redisDriver{}
+var (
+	_ cache.Cache              = &redisDriver{}
+	_ cache.ContextCache       = &redisDriver{}
+	_ cache.BatchCache         = &redisDriver{}
+	_ cache.PrefixCleanable    = &redisDriver{}
+	_ cache.Scanner            = &redisDriver{}
+	_ cache.ItemCache          = &redisDriver{}
+	_ cache.ConditionalCache   = &redisDriver{}
+	_ cache.SignedCounterCache = &redisDriver{}
+)
 
 const redisURL = "redis://localhost:6379?dial_timeout=1&db=1&read_timeout=1&write_timeout=1"
 
@@ -36,6 +47,7 @@ func TestRedis(t *testing.T) {
 	cachetest.Basic(a, c)
 	cachetest.Object(a, c)
 	cachetest.Counter(a, c)
+	cachetest.Batch(a, c.(cache.BatchCache))
 
 	a.NotError(c.Close())
 }
@@ -53,3 +65,83 @@ func TestRedis_Close(t *testing.T) {
 	var val string
 	a.NotError(c.Get("key", &val)).Equal(val, "val")
 }
+
+func TestRedis_codec(t *testing.T) {
+	a := assert.New(t, false)
+
+	c, err := NewFromURL(redisURL, WithCodec(caches.JSON))
+	a.NotError(err).NotNil(c)
+
+	cachetest.Basic(a, c)
+	cachetest.Object(a, c)
+	cachetest.Counter(a, c)
+
+	a.NotError(c.Close())
+}
+
+func TestRedis_CleanPrefix(t *testing.T) {
+	a := assert.New(t, false)
+
+	c, err := NewFromURL(redisURL)
+	a.NotError(err).NotNil(c)
+	d := c.(cache.PrefixCleanable)
+
+	a.NotError(c.Set("p1:k1", 1, cache.Forever)).
+		NotError(c.Set("p1:k2", 2, cache.Forever)).
+		NotError(c.Set("p1*k3", 3, cache.Forever)).
+		NotError(c.Set("p2:k1", 3, cache.Forever))
+
+	a.NotError(d.CleanPrefix("p1:"))
+	a.False(c.Exists("p1:k1")).
+		False(c.Exists("p1:k2")).
+		True(c.Exists("p2:k1"))
+
+	a.NotError(d.CleanPrefix("p1*"))
+	a.False(c.Exists("p1*k3")).True(c.Exists("p2:k1"))
+
+	a.NotError(c.Clean()).NotError(c.Close())
+}
+
+func TestRedis_Keys(t *testing.T) {
+	a := assert.New(t, false)
+
+	c, err := NewFromURL(redisURL)
+	a.NotError(err).NotNil(c)
+	cachetest.Keys(a, c)
+	a.NotError(c.Clean()).NotError(c.Close())
+}
+
+func TestRedis_GetItem(t *testing.T) {
+	a := assert.New(t, false)
+
+	c, err := NewFromURL(redisURL)
+	a.NotError(err).NotNil(c)
+	cachetest.Item(a, c, false)
+	a.NotError(c.Clean()).NotError(c.Close())
+}
+
+func TestRedis_Conditional(t *testing.T) {
+	a := assert.New(t, false)
+
+	c, err := NewFromURL(redisURL)
+	a.NotError(err).NotNil(c)
+	cachetest.Conditional(a, c)
+	a.NotError(c.Close())
+}
+
+func TestMilliseconds(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.Equal(milliseconds(cache.Forever), 0).
+		Equal(milliseconds(time.Microsecond), 1).
+		Equal(milliseconds(time.Second), 1000)
+}
+
+func TestRedis_SignedCounter(t *testing.T) {
+	a := assert.New(t, false)
+
+	c, err := NewFromURL(redisURL)
+	a.NotError(err).NotNil(c)
+	cachetest.SignedCounter(a, c)
+	a.NotError(c.Close())
+}
@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package caches
+
+import (
+	"math"
+
+	"github.com/issue9/localeutil"
+
+	"github.com/issue9/cache"
+)
+
+var errInvalidRange = localeutil.Error("the lower of counter must not be greater than upper")
+
+// CheckSignedRange 检测有符号计数器的范围是否有效
+//
+// lower 大于 upper 时返回错误，供驱动在创建计数器时使用。
+func CheckSignedRange(lower, upper int64) error {
+	if lower > upper {
+		return errInvalidRange
+	}
+	return nil
+}
+
+// SignedZero 有符号计数器的初始值
+//
+// 即离零最近的且在 [lower, upper] 范围之内的值。
+func SignedZero(lower, upper int64) int64 { return min(max(0, lower), upper) }
+
+// AddSigned 计算有符号计数器 v 加上 n 之后的值
+//
+// 溢出或是结果不在 [lower, upper] 范围之内时返回 v 和 [cache.ErrOutOfRange]。
+// 供驱动实现 [cache.SignedCounterCache] 使用。
+func AddSigned(v, n, lower, upper int64) (int64, error) {
+	if (n > 0 && v > math.MaxInt64-n) || (n < 0 && v < math.MinInt64-n) {
+		return v, cache.ErrOutOfRange()
+	}
+
+	if r := v + n; r >= lower && r <= upper {
+		return r, nil
+	}
+	return v, cache.ErrOutOfRange()
+}
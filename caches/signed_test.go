@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package caches
+
+import (
+	"math"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache"
+)
+
+func TestSignedZero(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.Equal(SignedZero(math.MinInt64, math.MaxInt64), 0).
+		Equal(SignedZero(5, 10), 5).
+		Equal(SignedZero(-10, -5), -5)
+}
+
+func TestCheckSignedRange(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.NotError(CheckSignedRange(0, 0)).
+		NotError(CheckSignedRange(math.MinInt64, math.MaxInt64)).
+		Error(CheckSignedRange(1, 0))
+}
+
+func TestAddSigned(t *testing.T) {
+	a := assert.New(t, false)
+
+	v, err := AddSigned(1, -3, math.MinInt64, math.MaxInt64)
+	a.NotError(err).Equal(v, -2)
+
+	v, err = AddSigned(math.MaxInt64, 1, math.MinInt64, math.MaxInt64)
+	a.ErrorIs(err, cache.ErrOutOfRange()).Equal(v, int64(math.MaxInt64))
+	v, err = AddSigned(math.MinInt64, -1, math.MinInt64, math.MaxInt64)
+	a.ErrorIs(err, cache.ErrOutOfRange()).Equal(v, int64(math.MinInt64))
+
+	v, err = AddSigned(5, 5, 0, 10)
+	a.NotError(err).Equal(v, 10)
+	v, err = AddSigned(5, 6, 0, 10)
+	a.ErrorIs(err, cache.ErrOutOfRange()).Equal(v, 5)
+	v, err = AddSigned(5, -6, 0, 10)
+	a.ErrorIs(err, cache.ErrOutOfRange()).Equal(v, 5)
+}
@@ -0,0 +1,171 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+// Package stale 在后端出错时提供旧值的装饰器
+package stale
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/issue9/localeutil"
+
+	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches"
+	"github.com/issue9/cache/caches/memory"
+)
+
+var errInvalidMaxStale = localeutil.Error("the maxStale must be greater than 0")
+
+type staleDriver struct {
+	driver     cache.Driver
+	ctx        cache.ContextCache
+	local      memory.Driver // 保存编码之后的旧值
+	maxStale   time.Duration
+	maxEntries int
+	codec      cache.Codec
+}
+
+// Option 用于设置 [New] 的参数
+type Option func(*staleDriver)
+
+// WithCodec 指定缓存值的编码方式
+//
+// 默认为 [caches.Default]。
+func WithCodec(c cache.Codec) Option {
+	return func(d *staleDriver) { d.codec = c }
+}
+
+// WithMaxEntries 限制本地保存的旧值数量
+//
+// 超出数量时，会淘汰最近最少使用的旧值。n 如果小于等于 0，表示不限制。
+func WithMaxEntries(n int) Option {
+	return func(d *staleDriver) { d.maxEntries = n }
+}
+
+// New 声明在后端出错时可以提供旧值的缓存
+//
+// 每次成功地从 d 读取或是写入 d 之后，都会在本地内存中保存一份该值，
+// 之后如果 d 返回了除 [cache.ErrCacheMiss] 之外的错误，比如网络故障，
+// [cache.Cache.Get] 会以本地保存的值填充目标，并返回包含了原始错误的 [cache.ErrStale]。
+// 返回对象实现了 [cache.StaleCache]，[cache.GetOrInit] 在 init 失败时也会返回本地的旧值。
+//
+// 本地的值在最后一次成功访问 maxStale 之后被回收，maxStale 必须大于 0，否则返回错误。
+// 通过 [cache.Cache.Delete] 和 [cache.Cleanable.Clean] 删除的值也会同时从本地删除。
+// 本地和 d 中保存的均是由 [WithCodec] 编码之后的 []byte，d 的编码方式参考 [cache.Codec]。
+//
+// 计数器的读写直接作用于 d，不会保存旧值。
+func New(d cache.Driver, maxStale time.Duration, o ...Option) (cache.Driver, error) {
+	if maxStale <= 0 {
+		return nil, errInvalidMaxStale
+	}
+
+	s := &staleDriver{
+		driver:   d,
+		ctx:      cache.AsContextCache(d),
+		maxStale: maxStale,
+		codec:    caches.Default,
+	}
+	for _, opt := range o {
+		opt(s)
+	}
+	s.local = memory.New(memory.WithJanitor(maxStale), memory.WithMaxEntries(s.maxEntries))
+	return s, nil
+}
+
+func (d *staleDriver) Get(key string, v any) error {
+	return d.GetContext(context.Background(), key, v)
+}
+
+func (d *staleDriver) GetContext(ctx context.Context, key string, v any) error {
+	var bs []byte
+	switch err := d.ctx.GetContext(ctx, key, &bs); {
+	case err == nil:
+		_ = d.local.Set(key, bs, d.maxStale) // 仅影响旧值的保存，不应该让成功的读取失败。
+		return d.codec.Unmarshal(bs, v)
+	case errors.Is(err, cache.ErrCacheMiss()):
+		return err
+	default:
+		if d.GetStale(key, v) == nil {
+			return errors.Join(cache.ErrStale(), err)
+		}
+		return err
+	}
+}
+
+func (d *staleDriver) GetStale(key string, v any) error {
+	var bs []byte
+	if err := d.local.Get(key, &bs); err != nil {
+		return err
+	}
+	return d.codec.Unmarshal(bs, v)
+}
+
+func (d *staleDriver) Set(key string, val any, ttl time.Duration) error {
+	return d.SetContext(context.Background(), key, val, ttl)
+}
+
+func (d *staleDriver) SetContext(ctx context.Context, key string, val any, ttl time.Duration) error {
+	bs, err := d.codec.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	if err := d.ctx.SetContext(ctx, key, bs, ttl); err != nil {
+		return err
+	}
+	return d.local.Set(key, bs, d.maxStale)
+}
+
+func (d *staleDriver) Delete(key string) error {
+	return d.DeleteContext(context.Background(), key)
+}
+
+func (d *staleDriver) DeleteContext(ctx context.Context, key string) error {
+	if err := d.ctx.DeleteContext(ctx, key); err != nil {
+		return err
+	}
+	return d.local.Delete(key)
+}
+
+func (d *staleDriver) Exists(key string) bool { return d.driver.Exists(key) }
+
+func (d *staleDriver) ExistsContext(ctx context.Context, key string) bool {
+	return d.ctx.ExistsContext(ctx, key)
+}
+
+func (d *staleDriver) Touch(key string, ttl time.Duration) error { return d.driver.Touch(key, ttl) }
+
+func (d *staleDriver) TouchContext(ctx context.Context, key string, ttl time.Duration) error {
+	return d.ctx.TouchContext(ctx, key, ttl)
+}
+
+func (d *staleDriver) Counter(key string, ttl time.Duration) (uint64, cache.SetCounterFunc, bool, error) {
+	return d.driver.Counter(key, ttl)
+}
+
+func (d *staleDriver) CounterContext(ctx context.Context, key string, ttl time.Duration) (uint64, cache.SetCounterFunc, bool, error) {
+	return d.ctx.CounterContext(ctx, key, ttl)
+}
+
+func (d *staleDriver) Clean() error {
+	if err := d.driver.Clean(); err != nil {
+		return err
+	}
+	return d.local.Clean()
+}
+
+func (d *staleDriver) CleanPrefix(prefix string) error {
+	if err := cache.CleanPrefix(d.driver, prefix); err != nil {
+		return err
+	}
+	return d.local.CleanPrefix(prefix)
+}
+
+func (d *staleDriver) Ping() error { return d.driver.Ping() }
+
+func (d *staleDriver) Close() error { return errors.Join(d.driver.Close(), d.local.Close()) }
+
+func (d *staleDriver) Driver() any { return d.driver.Driver() }
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package stale
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches/memory"
+	"github.com/issue9/cache/cachetest"
+)
+
+var (
+	_ cache.Driver          = &staleDriver{}
+	_ cache.ContextCache    = &staleDriver{}
+	_ cache.PrefixCleanable = &staleDriver{}
+	_ cache.StaleCache      = &staleDriver{}
+)
+
+var errDown = errors.New("down")
+
+func TestStale(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	c, err := New(d, time.Minute)
+	a.NotError(err).NotNil(c).Equal(c.Driver(), d.Driver())
+
+	cachetest.Basic(a, c)
+	cachetest.Object(a, c)
+	cachetest.Counter(a, c)
+	a.NotError(c.Close())
+
+	c, err = New(d, 0)
+	a.Error(err).Nil(c)
+}
+
+func TestStale_error(t *testing.T) {
+	a := assert.New(t, false)
+
+	fd := cachetest.Fail(memory.New())
+	c, err := New(fd, 500*time.Millisecond, WithMaxEntries(10))
+	a.NotError(err)
+
+	a.NotError(c.Set("k1", "v1", cache.Forever))
+	var v string
+	a.NotError(c.Get("k1", &v)).Equal(v, "v1")
+
+	fd.Down(errDown)
+	v = ""
+	err = c.Get("k1", &v)
+	a.ErrorIs(err, cache.ErrStale()).ErrorIs(err, errDown).Equal(v, "v1")
+
+	// 不存在旧值
+	err = c.Get("not-exists", &v)
+	a.ErrorIs(err, errDown).False(errors.Is(err, cache.ErrStale()))
+
+	// GetOrInit 透传错误
+	err = cache.GetOrInit(c, "k1", &v, cache.Forever, func(*string) error { return nil })
+	a.ErrorIs(err, cache.ErrStale()).Equal(v, "v1")
+
+	// 超过 maxStale
+	time.Sleep(time.Second)
+	err = c.Get("k1", &v)
+	a.ErrorIs(err, errDown).False(errors.Is(err, cache.ErrStale()))
+
+	// 删除之后不再有旧值
+	fd.Down(nil)
+	a.NotError(c.Set("k2", "v2", cache.Forever))
+	a.NotError(c.Delete("k2"))
+	fd.Down(errDown)
+	err = c.Get("k2", &v)
+	a.ErrorIs(err, errDown).False(errors.Is(err, cache.ErrStale()))
+}
+
+func TestStale_GetOrInit(t *testing.T) {
+	a := assert.New(t, false)
+
+	c, err := New(memory.New(), time.Minute)
+	a.NotError(err)
+	errInit := errors.New("init")
+
+	var v int
+	a.NotError(cache.GetOrInit(c, "k1", &v, 500*time.Millisecond, func(v *int) error { *v = 1; return nil })).
+		Equal(v, 1)
+
+	time.Sleep(time.Second) // 在后端过期
+
+	v = 0
+	err = cache.GetOrInit(c, "k1", &v, time.Second, func(*int) error { return errInit })
+	a.ErrorIs(err, cache.ErrStale()).ErrorIs(err, errInit).Equal(v, 1)
+
+	// 通过 Prefix 访问
+	p := cache.Prefix(c, "p:")
+	a.NotError(p.Set("k2", 2, 500*time.Millisecond))
+	time.Sleep(time.Second)
+	v = 0
+	err = cache.GetOrInit(p, "k2", &v, time.Second, func(*int) error { return errInit })
+	a.ErrorIs(err, cache.ErrStale()).Equal(v, 2)
+
+	// 不存在旧值
+	err = cache.GetOrInit(c, "k3", &v, time.Second, func(*int) error { return errInit })
+	a.Equal(err, errInit)
+
+	// XFetch
+	a.NotError(cache.GetOrInit(c, "k4", &v, 500*time.Millisecond, func(v *int) error { *v = 4; return nil }, cache.XFetch(1)))
+	time.Sleep(time.Second)
+	v = 0
+	err = cache.GetOrInit(c, "k4", &v, time.Second, func(*int) error { return errInit }, cache.XFetch(1))
+	a.ErrorIs(err, cache.ErrStale()).Equal(v, 4)
+}
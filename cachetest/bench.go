@@ -1,10 +1,13 @@
-// SPDX-FileCopyrightText: 2024 caixw
+// SPDX-FileCopyrightText: 2024-2026 caixw
 //
 // SPDX-License-Identifier: MIT
 
 package cachetest
 
 import (
+	"errors"
+	"math/rand/v2"
+	"strconv"
 	"testing"
 	"time"
 
@@ -93,3 +96,83 @@ func BenchObject(b *testing.B, c cache.Driver) {
 		}
 	})
 }
+
+// BenchParallel 测试并发读写的性能
+//
+// 多个 goroutine 同时对一组 key 进行读写，其中写入和读取的比例为 1:9。
+func BenchParallel(b *testing.B, d cache.Driver) {
+	const keys = 1024
+
+	a := assert.New(b, false)
+	for i := range keys {
+		a.NotError(d.Set("p"+strconv.Itoa(i), i, cache.Forever))
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+		var v int
+		for pb.Next() {
+			i := r.IntN(keys)
+			key := "p" + strconv.Itoa(i)
+			if i%10 == 0 {
+				a.NotError(d.Set(key, i, cache.Forever))
+			} else {
+				a.NotError(d.Get(key, &v))
+			}
+		}
+	})
+}
+
+// BenchHitRatio 测试在 Zipf 分布的访问下的命中率
+//
+// 每次访问未命中时都会写入该缓存项，命中率以 hit-ratio 指标输出，
+// 主要用于比较有容量限制的驱动在不同淘汰策略下的表现。
+// 其中 zipf-scan 会在访问过程中穿插一次性的遍历访问。
+//
+// 测试前后会调用 [cache.Driver.Clean] 清除所有内容。
+func BenchHitRatio(b *testing.B, d cache.Driver) {
+	b.Run("zipf", func(b *testing.B) { benchHitRatio(b, d, false) })
+	b.Run("zipf-scan", func(b *testing.B) { benchHitRatio(b, d, true) })
+}
+
+func benchHitRatio(b *testing.B, d cache.Driver, scan bool) {
+	const (
+		keys      = 100_000
+		scanEvery = 1000 // 每隔多少次访问执行一次遍历
+		scanSize  = 500  // 每次遍历的数量
+	)
+
+	a := assert.New(b, false)
+	a.NotError(d.Clean())
+	defer func() { a.NotError(d.Clean()) }()
+
+	zipf := rand.NewZipf(rand.New(rand.NewPCG(1, 2)), 1.01, 1, keys-1)
+	var hits, total, scanned int
+
+	access := func(key string) {
+		total++
+		var v int
+		switch err := d.Get(key, &v); {
+		case err == nil:
+			hits++
+		case errors.Is(err, cache.ErrCacheMiss()):
+			a.NotError(d.Set(key, total, cache.Forever))
+		default:
+			a.NotError(err)
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		access(strconv.FormatUint(zipf.Uint64(), 10))
+
+		if scan && i%scanEvery == 0 {
+			for range scanSize {
+				access("scan-" + strconv.Itoa(scanned))
+				scanned++
+			}
+		}
+	}
+	b.ReportMetric(float64(hits)/float64(total), "hit-ratio")
+}
@@ -6,6 +6,8 @@
 package cachetest
 
 import (
+	"math"
+	"slices"
 	"time"
 
 	"github.com/issue9/assert/v4"
@@ -54,6 +56,72 @@ func Counter(a *assert.Assertion, d cache.Driver) {
 	a.NotError(err).Equal(v2, 0)
 }
 
+// SignedCounter 测试有符号的计数器
+//
+// c 需要实现 [cache.SignedCounterCache]。
+func SignedCounter(a *assert.Assertion, c cache.Cache) {
+	sc, ok := c.(cache.SignedCounterCache)
+	a.True(ok)
+
+	n, set, found, err := sc.SignedCounter("s1", time.Second, math.MinInt64, math.MaxInt64)
+	a.NotError(err).Zero(n).NotNil(set).False(found)
+
+	v, err := set(0)
+	a.NotError(err).Equal(v, 0)
+	v, err = set(5)
+	a.NotError(err).Equal(v, 5)
+	v, err = set(-10) // 可以小于 0
+	a.NotError(err).Equal(v, -5)
+	v, err = set(-3)
+	a.NotError(err).Equal(v, -8)
+
+	// 溢出
+	v, err = set(math.MinInt64)
+	a.ErrorIs(err, cache.ErrOutOfRange()).Equal(v, -8)
+	v, err = set(0)
+	a.NotError(err).Equal(v, -8)
+
+	a.NotError(c.Delete("s1"))
+	v, err = set(3) // 已经被删除
+	a.ErrorIs(err, cache.ErrCacheMiss()).Zero(v)
+
+	// 范围
+	n, set, found, err = sc.SignedCounter("s2", time.Second, 0, 10)
+	a.NotError(err).Zero(n).False(found)
+	v, err = set(10)
+	a.NotError(err).Equal(v, 10)
+	v, err = set(1)
+	a.ErrorIs(err, cache.ErrOutOfRange()).Equal(v, 10)
+	v, err = set(-11)
+	a.ErrorIs(err, cache.ErrOutOfRange()).Equal(v, 10)
+	v, err = set(-10)
+	a.NotError(err).Equal(v, 0)
+
+	// 零不在范围之内
+	n, set, found, err = sc.SignedCounter("s3", time.Second, 5, 10)
+	a.NotError(err).Equal(n, 5).False(found)
+	v, err = set(-1)
+	a.ErrorIs(err, cache.ErrOutOfRange()).Equal(v, 5)
+
+	// 多个计数器指向同一个 key
+	n, set, found, err = sc.SignedCounter("s2", time.Second, -10, 10)
+	a.NotError(err).Equal(n, 0).True(found)
+	v, err = set(-10)
+	a.NotError(err).Equal(v, -10)
+
+	// 与 Counter 共用同一个值
+	_, setU, _, err := c.Counter("s4", time.Second)
+	a.NotError(err)
+	_, err = setU(5)
+	a.NotError(err)
+	n, _, found, err = sc.SignedCounter("s4", time.Second, math.MinInt64, math.MaxInt64)
+	a.NotError(err).Equal(n, 5).True(found)
+
+	// 无效的范围
+	_, set, _, err = sc.SignedCounter("s5", time.Second, 10, 0)
+	a.Error(err).Nil(set).False(c.Exists("s5"))
+}
+
 // Basic 测试基本功能
 func Basic(a *assert.Assertion, c cache.Driver) {
 	// driver
@@ -126,3 +194,181 @@ func Object(a *assert.Assertion, c cache.Driver) {
 	err := c.Get("obj", &v)
 	a.NotError(err).Equal(&v, &object{Name: "test"}) // 私有字段，无法解码
 }
+
+// Batch 测试批量操作
+func Batch(a *assert.Assertion, c cache.BatchCache) {
+	a.NotError(c.SetMulti(map[string]any{"b1": 1, "b2": "2", "b3": &object{Name: "3"}}, time.Second))
+
+	var v1 int
+	var v2 string
+	var v3 object
+	var v4 int
+	items := map[string]any{"b1": &v1, "b2": &v2, "b3": &v3, "not_exists": &v4}
+	a.NotError(c.GetMulti(items)).
+		Length(items, 3).
+		Equal(v1, 1).
+		Equal(v2, "2").
+		Equal(v3, object{Name: "3"}).
+		Zero(v4)
+	_, found := items["not_exists"]
+	a.False(found)
+
+	a.NotError(c.DeleteMulti("b1", "b2", "not_exists"))
+	a.False(c.Exists("b1")).False(c.Exists("b2")).True(c.Exists("b3"))
+
+	// 超时被回收
+	time.Sleep(2 * time.Second)
+	items = map[string]any{"b3": &v3}
+	a.NotError(c.GetMulti(items)).Empty(items)
+
+	// 空值
+	a.NotError(c.GetMulti(map[string]any{})).
+		NotError(c.SetMulti(map[string]any{}, time.Second)).
+		NotError(c.DeleteMulti())
+}
+
+// Keys 测试键名的遍历
+//
+// c 需要实现 [cache.Scanner]。
+func Keys(a *assert.Assertion, c cache.Cache) {
+	s, ok := c.(cache.Scanner)
+	a.True(ok)
+
+	for _, k := range []string{"keys:1", "keys:2", "keys:10", "keys*3", "other"} {
+		a.NotError(c.Set(k, 1, time.Minute))
+	}
+
+	collect := func(pattern string) []string {
+		keys := make([]string, 0, 5)
+		for k, err := range s.Keys(pattern) {
+			a.NotError(err)
+			if !slices.Contains(keys, k) { // 部分实现可能返回重复的值
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		return keys
+	}
+
+	a.Equal(collect("keys:*"), []string{"keys:1", "keys:10", "keys:2"}).
+		Equal(collect("keys:?"), []string{"keys:1", "keys:2"}).
+		Equal(collect(cache.EscapePattern("keys*")+"*"), []string{"keys*3"}).
+		Equal(collect("not-exists*"), []string{})
+
+	// 中途退出
+	count := 0
+	for range s.Keys("keys*") {
+		count++
+		break
+	}
+	a.Equal(count, 1)
+
+	a.NotError(cache.AsBatchCache(c).DeleteMulti("keys:1", "keys:2", "keys:10", "keys*3", "other"))
+}
+
+// Item 测试元数据的获取
+//
+// c 需要实现 [cache.ItemCache]，full 表示是否可以提供 [cache.Item] 的所有字段。
+func Item(a *assert.Assertion, c cache.Cache, full bool) {
+	ic, ok := c.(cache.ItemCache)
+	a.True(ok)
+
+	start := time.Now()
+	a.NotError(c.Set("item", &object{Name: "item"}, time.Minute))
+
+	var v object
+	item, err := ic.GetItem("item", &v)
+	a.NotError(err).NotNil(item).
+		Equal(v, object{Name: "item"}).
+		True(item.TTL > 50*time.Second && item.TTL <= time.Minute, item.TTL)
+	if full {
+		a.Equal(item.OriginalTTL, time.Minute).
+			True(!item.Created.Before(start.Truncate(time.Second)) && !item.Created.After(time.Now()))
+	}
+
+	ttl, err := ic.TTL("item")
+	a.NotError(err).True(ttl > 50*time.Second && ttl <= time.Minute, ttl)
+
+	// Touch
+	a.NotError(c.Touch("item", time.Hour))
+	item, err = ic.GetItem("item", &v)
+	a.NotError(err).True(item.TTL > 50*time.Minute && item.TTL <= time.Hour, item.TTL)
+	if full {
+		a.Equal(item.OriginalTTL, time.Hour)
+	}
+
+	// Forever
+	a.NotError(c.Set("item", 1, cache.Forever))
+	var num int
+	item, err = ic.GetItem("item", &num)
+	a.NotError(err).Equal(num, 1).Equal(item.TTL, cache.Forever)
+	ttl, err = ic.TTL("item")
+	a.NotError(err).Equal(ttl, cache.Forever)
+
+	// 不存在
+	item, err = ic.GetItem("not-exists", &num)
+	a.ErrorIs(err, cache.ErrCacheMiss()).Nil(item)
+	_, err = ic.TTL("not-exists")
+	a.ErrorIs(err, cache.ErrCacheMiss())
+
+	a.NotError(c.Delete("item"))
+}
+
+// Conditional 测试条件写入
+//
+// c 需要实现 [cache.ConditionalCache]。
+func Conditional(a *assert.Assertion, c cache.Cache) {
+	cc, ok := c.(cache.ConditionalCache)
+	a.True(ok)
+
+	// Add
+	a.NotError(cc.Add("cond", 1, time.Minute)).
+		ErrorIs(cc.Add("cond", 2, time.Minute), cache.ErrNotStored())
+	var v int
+	a.NotError(c.Get("cond", &v)).Equal(v, 1)
+
+	// Replace
+	a.NotError(cc.Replace("cond", 3, time.Minute)).
+		ErrorIs(cc.Replace("not-exists", 3, time.Minute), cache.ErrNotStored()).
+		False(c.Exists("not-exists"))
+	a.NotError(c.Get("cond", &v)).Equal(v, 3)
+
+	// CompareAndSwap
+	token, err := cc.GetCAS("cond", &v)
+	a.NotError(err).Equal(v, 3)
+	a.NotError(cc.CompareAndSwap("cond", 4, token, time.Minute)).
+		ErrorIs(cc.CompareAndSwap("cond", 5, token, time.Minute), cache.ErrNotStored())
+	a.NotError(c.Get("cond", &v)).Equal(v, 4)
+
+	token, err = cc.GetCAS("cond", &v)
+	a.NotError(err).Equal(v, 4)
+	a.NotError(c.Set("cond", 6, time.Minute))
+	a.ErrorIs(cc.CompareAndSwap("cond", 7, token, time.Minute), cache.ErrNotStored())
+
+	_, err = cc.GetCAS("not-exists", &v)
+	a.ErrorIs(err, cache.ErrCacheMiss())
+	a.ErrorIs(cc.CompareAndSwap("not-exists", 1, token, time.Minute), cache.ErrCacheMiss())
+
+	// CompareAndDelete
+	token, err = cc.GetCAS("cond", &v)
+	a.NotError(err).Equal(v, 6)
+	a.NotError(c.Set("cond", 7, time.Minute))
+	a.ErrorIs(cc.CompareAndDelete("cond", token), cache.ErrNotStored()).
+		True(c.Exists("cond"))
+	token, err = cc.GetCAS("cond", &v)
+	a.NotError(err).Equal(v, 7)
+	a.NotError(cc.CompareAndDelete("cond", token)).
+		False(c.Exists("cond")).
+		ErrorIs(cc.CompareAndDelete("cond", token), cache.ErrCacheMiss())
+
+	// 删除之后可以再次 Add
+	a.NotError(c.Delete("cond")).
+		NotError(cc.Add("cond", 8, time.Second))
+	a.NotError(c.Get("cond", &v)).Equal(v, 8)
+
+	// 过期之后可以再次 Add
+	time.Sleep(2 * time.Second)
+	a.NotError(cc.Add("cond", 9, time.Minute))
+
+	a.NotError(c.Delete("cond"))
+}
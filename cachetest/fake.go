@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cachetest
+
+import (
+	"sync/atomic"
+
+	"github.com/issue9/cache"
+)
+
+// 嵌入 [cache.Driver] 时，字段名会与 [cache.Driver.Driver] 方法冲突，所以需要一个别名。
+type backend = cache.Driver
+
+type plainDriver struct {
+	backend
+}
+
+// FailDriver 可以模拟后端故障的驱动
+//
+// 与 [Plain] 相同，仅提供 [cache.Driver] 的功能。
+type FailDriver struct {
+	backend
+	err atomic.Pointer[error]
+}
+
+// Plain 返回仅实现了 [cache.Driver] 的对象
+//
+// 所有的操作均交由 d 执行，但是隐藏了 d 实现的其它接口，
+// 比如 [cache.ContextCache] 和 [cache.ConditionalCache] 等，
+// 可用于测试依赖这些接口的功能在未实现时的行为。
+func Plain(d cache.Driver) cache.Driver { return &plainDriver{backend: d} }
+
+// Fail 声明 [FailDriver]
+func Fail(d cache.Driver) *FailDriver { return &FailDriver{backend: d} }
+
+// Down 模拟后端故障
+//
+// 之后的 Get 均返回 err，err 为空表示恢复正常。
+func (d *FailDriver) Down(err error) {
+	if err == nil {
+		d.err.Store(nil)
+	} else {
+		d.err.Store(&err)
+	}
+}
+
+func (d *FailDriver) Get(key string, v any) error {
+	if err := d.err.Load(); err != nil {
+		return *err
+	}
+	return d.backend.Get(key, v)
+}
@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/issue9/localeutil"
+)
+
+var errNotStored = localeutil.Error("cache item not stored")
+
+// ConditionalCache 支持条件写入的缓存
+//
+// 条件不满足时，各方法均返回 [ErrNotStored]。
+type ConditionalCache interface {
+	// Add 仅在 key 不存在时写入
+	Add(key string, val any, ttl time.Duration) error
+
+	// Replace 仅在 key 存在时写入
+	Replace(key string, val any, ttl time.Duration) error
+
+	// GetCAS 获取缓存项的值以及当前的版本标记
+	//
+	// key 和 v 与 [Cache.Get] 相同，返回的 token 用于 [ConditionalCache.CompareAndSwap]。
+	GetCAS(key string, v any) (token uint64, err error)
+
+	// CompareAndSwap 仅在缓存项的版本标记依然为 token 时写入
+	//
+	// 缓存项不存在时返回 [ErrCacheMiss]。
+	CompareAndSwap(key string, val any, token uint64, ttl time.Duration) error
+
+	// CompareAndDelete 仅在缓存项的版本标记依然为 token 时删除
+	//
+	// 缓存项不存在时返回 [ErrCacheMiss]。
+	CompareAndDelete(key string, token uint64) error
+}
+
+// ErrNotStored 条件写入时因条件不满足而未写入的错误
+func ErrNotStored() error { return errNotStored }
+
+func (p *prefix) conditional() (ConditionalCache, string, error) {
+	cc, ok := unwrapContextCache(p.cache).(ConditionalCache)
+	if !ok {
+		return nil, "", errors.ErrUnsupported
+	}
+
+	b, err := p.base(context.Background())
+	if err != nil {
+		return nil, "", err
+	}
+	return cc, b, nil
+}
+
+func (p *prefix) marshal(val any) (any, error) {
+	if p.codec == nil {
+		return val, nil
+	}
+	return p.codec.Marshal(val)
+}
+
+// Add 实现 [ConditionalCache] 接口
+//
+// 如果底层的缓存未实现 [ConditionalCache]，返回 [errors.ErrUnsupported]，其它方法也相同。
+func (p *prefix) Add(key string, val any, ttl time.Duration) error {
+	cc, b, err := p.conditional()
+	if err != nil {
+		return err
+	}
+
+	if val, err = p.marshal(val); err != nil {
+		return err
+	}
+	return cc.Add(b+key, val, ttl)
+}
+
+func (p *prefix) Replace(key string, val any, ttl time.Duration) error {
+	cc, b, err := p.conditional()
+	if err != nil {
+		return err
+	}
+
+	if val, err = p.marshal(val); err != nil {
+		return err
+	}
+	return cc.Replace(b+key, val, ttl)
+}
+
+func (p *prefix) GetCAS(key string, v any) (uint64, error) {
+	cc, b, err := p.conditional()
+	if err != nil {
+		return 0, err
+	}
+
+	if p.codec == nil {
+		return cc.GetCAS(b+key, v)
+	}
+
+	var bs []byte
+	token, err := cc.GetCAS(b+key, &bs)
+	if err != nil {
+		return 0, err
+	}
+	return token, p.codec.Unmarshal(bs, v)
+}
+
+func (p *prefix) CompareAndSwap(key string, val any, token uint64, ttl time.Duration) error {
+	cc, b, err := p.conditional()
+	if err != nil {
+		return err
+	}
+
+	if val, err = p.marshal(val); err != nil {
+		return err
+	}
+	return cc.CompareAndSwap(b+key, val, token, ttl)
+}
+
+func (p *prefix) CompareAndDelete(key string, token uint64) error {
+	cc, b, err := p.conditional()
+	if err != nil {
+		return err
+	}
+	return cc.CompareAndDelete(b+key, token)
+}
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+type contextCache struct {
+	Cache
+}
+
+// AsContextCache 将 c 转换为 [ContextCache]
+//
+// 如果 c 本身已经实现了 [ContextCache]，则直接返回 c，
+// 否则返回的对象仅在调用之前检测 ctx 是否已经取消，并不会将 ctx 传递给 c。
+func AsContextCache(c Cache) ContextCache {
+	if cc, ok := c.(ContextCache); ok {
+		return cc
+	}
+	return &contextCache{Cache: c}
+}
+
+// 如果 c 是由 [AsContextCache] 包装的对象，返回原始的对象。
+func unwrapContextCache(c Cache) Cache {
+	if cc, ok := c.(*contextCache); ok {
+		return cc.Cache
+	}
+	return c
+}
+
+func (c *contextCache) GetContext(ctx context.Context, key string, v any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Get(key, v)
+}
+
+func (c *contextCache) SetContext(ctx context.Context, key string, val any, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Set(key, val, ttl)
+}
+
+func (c *contextCache) DeleteContext(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Delete(key)
+}
+
+func (c *contextCache) ExistsContext(ctx context.Context, key string) bool {
+	return ctx.Err() == nil && c.Exists(key)
+}
+
+func (c *contextCache) TouchContext(ctx context.Context, key string, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Touch(key, ttl)
+}
+
+func (c *contextCache) CounterContext(ctx context.Context, key string, ttl time.Duration) (uint64, SetCounterFunc, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, nil, false, err
+	}
+
+	n, f, exist, err := c.Counter(key, ttl)
+	if err != nil {
+		return 0, nil, false, err
+	}
+
+	return n, func(n int) (uint64, error) {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		return f(n)
+	}, exist, nil
+}
@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches/memory"
+)
+
+func TestAsContextCache(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	p := cache.Prefix(d, "p:") // 已实现 ContextCache
+	a.Equal(cache.AsContextCache(p), p)
+
+	c := cache.AsContextCache(d)
+	a.NotNil(c).NotEqual(c, d)
+
+	ctx := context.Background()
+	a.NotError(c.SetContext(ctx, "k1", 5, cache.Forever)).
+		True(c.ExistsContext(ctx, "k1"))
+	var v int
+	a.NotError(c.GetContext(ctx, "k1", &v)).Equal(v, 5).
+		NotError(c.TouchContext(ctx, "k1", time.Second))
+
+	n, f, exist, err := c.CounterContext(ctx, "n1", cache.Forever)
+	a.NotError(err).Zero(n).False(exist).NotNil(f)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	a.ErrorIs(c.GetContext(canceled, "k1", &v), context.Canceled).
+		ErrorIs(c.SetContext(canceled, "k1", 5, cache.Forever), context.Canceled).
+		ErrorIs(c.TouchContext(canceled, "k1", cache.Forever), context.Canceled).
+		ErrorIs(c.DeleteContext(canceled, "k1"), context.Canceled).
+		False(c.ExistsContext(canceled, "k1"))
+	_, _, _, err = c.CounterContext(canceled, "n1", cache.Forever)
+	a.ErrorIs(err, context.Canceled)
+
+	a.NotError(c.DeleteContext(ctx, "k1")).
+		False(c.ExistsContext(ctx, "k1"))
+}
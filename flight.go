@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"reflect"
+	"sync"
+)
+
+// 对同一缓存项的并发调用进行合并
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[flightKey]*flightCall
+}
+
+type flightKey struct {
+	cache Cache // 去掉 [Prefix] 等包装之后的缓存对象
+	key   string
+}
+
+type flightCall struct {
+	wg       sync.WaitGroup
+	val      any
+	err      error
+	panicked bool
+	panicVal any
+}
+
+var flights = &flightGroup{calls: make(map[flightKey]*flightCall)}
+
+// 返回 c 中 key 对应的唯一标记
+//
+// 通过 [Prefix] 访问时，会转换为底层的缓存对象和完整的键名，
+// 保证不同的 [Prefix] 对象访问同一缓存项时也能被合并。
+// 如果 c 无法作为 map 的键名，返回 false。
+func flightKeyOf(c Cache, key string) (flightKey, bool) {
+LOOP:
+	for {
+		switch cc := c.(type) {
+		case *prefix:
+			c = cc.cache
+			key = cc.prefix + key
+		case *contextCache:
+			c = cc.Cache
+		default:
+			break LOOP
+		}
+	}
+
+	if c == nil || !reflect.TypeOf(c).Comparable() {
+		return flightKey{}, false
+	}
+	return flightKey{cache: c, key: key}, true
+}
+
+// 执行 f，同一 k 的并发调用仅会执行一次 f，其它调用者等待并共享其返回值。
+//
+// shared 表示返回值是否来自其它调用者；如果 f 发生 panic，所有调用者都会以相同的值 panic。
+func (g *flightGroup) do(k flightKey, f func() (any, error)) (val any, err error, shared bool) {
+	g.mu.Lock()
+	if c, found := g.calls[k]; found {
+		g.mu.Unlock()
+		c.wg.Wait()
+		if c.panicked {
+			panic(c.panicVal)
+		}
+		return c.val, c.err, true
+	}
+
+	c := &flightCall{}
+	c.wg.Add(1)
+	g.calls[k] = c
+	g.mu.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			c.panicked = true
+			c.panicVal = r
+		}
+
+		g.mu.Lock()
+		delete(g.calls, k)
+		g.mu.Unlock()
+		c.wg.Done()
+
+		if c.panicked {
+			panic(c.panicVal)
+		}
+	}()
+
+	c.val, c.err = f()
+	return c.val, c.err, false
+}
+
+// 缓存中保存的带有额外信息的值
+type envelope[T any] interface {
+	value() T
+}
+
+// 通过 flights 执行 load，合并对同一缓存项的并发调用。
+//
+// load 负责写入 *v 并返回其值，合并的调用者从该返回值中获取 *v。
+// 合并的调用者之间 T 可能不同，此时改由 get 从缓存中读取，
+// 如果缓存中保存的是 env 类型的对象，则从 env 中获取 *v，env 为空表示直接读取至 v。
+func loadShared[T any](c Cache, key string, v *T, load func() (any, error), get func(any) error, env envelope[T]) error {
+	fk, ok := flightKeyOf(c, key)
+	if !ok {
+		_, err := load()
+		return err
+	}
+
+	val, err, shared := flights.do(fk, load)
+	if err != nil || !shared {
+		return err
+	}
+	if vv, ok := val.(T); ok {
+		*v = vv
+		return nil
+	}
+
+	if env == nil {
+		return get(v)
+	}
+	if err := get(env); err != nil {
+		return err
+	}
+	*v = env.value()
+	return nil
+}
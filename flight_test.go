@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+)
+
+type mapCache map[string]any
+
+func (c mapCache) Get(string, any) error                { return ErrCacheMiss() }
+func (c mapCache) Set(string, any, time.Duration) error { return nil }
+func (c mapCache) Delete(string) error                  { return nil }
+func (c mapCache) Exists(string) bool                   { return false }
+func (c mapCache) Touch(string, time.Duration) error    { return nil }
+func (c mapCache) Counter(string, time.Duration) (uint64, SetCounterFunc, bool, error) {
+	return 0, nil, false, nil
+}
+
+type ptrCache struct{ mapCache }
+
+func TestFlightKeyOf(t *testing.T) {
+	a := assert.New(t, false)
+
+	c := &ptrCache{}
+	k, ok := flightKeyOf(c, "key")
+	a.True(ok).Equal(k, flightKey{cache: c, key: "key"})
+
+	k, ok = flightKeyOf(Prefix(Prefix(c, "p1"), "p2"), "key")
+	a.True(ok).Equal(k, flightKey{cache: c, key: "p1p2key"})
+
+	_, ok = flightKeyOf(mapCache{}, "key")
+	a.False(ok)
+
+	_, ok = flightKeyOf(Prefix(mapCache{}, "p1"), "key")
+	a.False(ok)
+}
+
+func TestFlightGroup_do(t *testing.T) {
+	a := assert.New(t, false)
+
+	g := &flightGroup{calls: make(map[flightKey]*flightCall)}
+	k := flightKey{key: "key"}
+
+	var calls atomic.Int32
+	var sharedCount atomic.Int32
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			val, err, shared := g.do(k, func() (any, error) {
+				calls.Add(1)
+				time.Sleep(100 * time.Millisecond)
+				return 5, nil
+			})
+			a.NotError(err).Equal(val, 5)
+			if shared {
+				sharedCount.Add(1)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	a.Equal(calls.Load(), 1).Equal(sharedCount.Load(), 9).Empty(g.calls)
+
+	// error
+	errTest := errors.New("test")
+	_, err, shared := g.do(k, func() (any, error) { return nil, errTest })
+	a.ErrorIs(err, errTest).False(shared)
+
+	// panic
+	a.PanicValue(func() {
+		g.do(k, func() (any, error) { panic("panic") })
+	}, "panic")
+	a.Empty(g.calls)
+}
@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Item 缓存项的元数据
+//
+// 部分驱动无法提供所有的字段，无法获取的时长以负数表示，时间以零值表示。
+type Item struct {
+	// TTL 剩余的有效时间
+	//
+	// 为 [Forever] 表示永不过期。
+	TTL time.Duration
+
+	// OriginalTTL 写入或是最后一次调用 [Cache.Touch] 时指定的 ttl
+	OriginalTTL time.Duration
+
+	// Created 写入的时间
+	Created time.Time
+}
+
+// ItemCache 可以获取缓存项元数据的缓存
+type ItemCache interface {
+	// GetItem 获取缓存项的值以及元数据
+	//
+	// key 和 v 与 [Cache.Get] 相同。
+	GetItem(key string, v any) (*Item, error)
+
+	// TTL 获取缓存项剩余的有效时间
+	//
+	// 为 [Forever] 表示永不过期，缓存项不存在时返回 [ErrCacheMiss]。
+	TTL(key string) (time.Duration, error)
+}
+
+// GetItem 获取缓存项的值以及元数据
+//
+// 如果 c 未实现 [ItemCache]，返回 [errors.ErrUnsupported]。
+func GetItem[T any](c Cache, key string) (T, *Item, error) {
+	var val T
+	ic, ok := unwrapContextCache(c).(ItemCache)
+	if !ok {
+		return val, nil, errors.ErrUnsupported
+	}
+
+	item, err := ic.GetItem(key, &val)
+	return val, item, err
+}
+
+func (p *prefix) GetItem(key string, v any) (*Item, error) {
+	ic, ok := unwrapContextCache(p.cache).(ItemCache)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+
+	b, err := p.base(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	if p.codec == nil {
+		return ic.GetItem(b+key, v)
+	}
+
+	var bs []byte
+	item, err := ic.GetItem(b+key, &bs)
+	if err != nil {
+		return nil, err
+	}
+	return item, p.codec.Unmarshal(bs, v)
+}
+
+func (p *prefix) TTL(key string) (time.Duration, error) {
+	ic, ok := unwrapContextCache(p.cache).(ItemCache)
+	if !ok {
+		return 0, errors.ErrUnsupported
+	}
+
+	b, err := p.base(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	return ic.TTL(b + key)
+}
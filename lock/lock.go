@@ -0,0 +1,251 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+// Package lock 基于缓存的分布式锁
+package lock
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/issue9/localeutil"
+
+	"github.com/issue9/cache"
+)
+
+// 锁的最小有效时长
+//
+// 同时也保证了 [Lock.Do] 中续期的间隔不为 0。
+const minTTL = time.Millisecond
+
+var (
+	errNotHeld        = localeutil.Error("lock not held")
+	errInvalidTTL     = localeutil.Error("the ttl of lock must not be less than 1ms")
+	errInvalidBackoff = localeutil.Error("invalid backoff of lock")
+)
+
+// Lock 分布式锁
+//
+// 锁以缓存项的形式保存，值为当前持有者的随机标记，
+// 获取时以 [cache.ConditionalCache.Add] 写入，释放和续期时会先比较持有者，
+// 所以即使锁已经过期并被其它进程获取，也不会误删或是误续期其它进程的锁。
+//
+// 同一个 [Lock] 对象不可重入，也不应该在多个 goroutine 中同时使用。
+type Lock struct {
+	cache cache.ConditionalCache
+	key   string
+	ttl   time.Duration
+	owner string
+
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// Option 用于设置 [New] 的参数
+type Option func(*Lock)
+
+// WithBackoff 指定 [Lock.Lock] 重试的间隔
+//
+// 每次重试的间隔从 min 开始翻倍，直到 max，实际的间隔会加入随机抖动。
+// 默认为 10 毫秒至 1 秒。min 必须大于 0 且不大于 max，否则 [New] 返回错误。
+func WithBackoff(min, max time.Duration) Option {
+	return func(l *Lock) {
+		l.minBackoff = min
+		l.maxBackoff = max
+	}
+}
+
+// New 声明分布式锁
+//
+// key 为锁在 c 中的键名，ttl 为锁的有效时长，在此时间之后即使未调用 [Lock.Unlock] 锁也会自动释放，
+// 以防止持有者崩溃之后锁永远无法被获取。ttl 不能小于 1 毫秒，
+// 实际的精度取决于 c，比如 memcached 会向上取整到秒。
+//
+// 如果 c 未实现 [cache.ConditionalCache]，返回 [errors.ErrUnsupported]。
+// 由 [cache.Prefix] 等包装的对象，即使实现了该接口，也可能因为底层缓存不支持而无法使用，
+// 所以 New 会以 [cache.ConditionalCache.GetCAS] 读取一次 key 以确认其可用性。
+//
+// 每次调用 New 都会生成新的持有者标记，多个进程或是 goroutine 应该各自调用 New。
+func New(c cache.Cache, key string, ttl time.Duration, o ...Option) (*Lock, error) {
+	if ttl < minTTL {
+		return nil, errInvalidTTL
+	}
+
+	cc, ok := c.(cache.ConditionalCache)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	var owner string
+	if _, err := cc.GetCAS(key, &owner); err != nil && !errors.Is(err, cache.ErrCacheMiss()) {
+		return nil, err
+	}
+
+	l := &Lock{
+		cache: cc,
+		key:   key,
+		ttl:   ttl,
+		owner: newOwner(),
+
+		minBackoff: 10 * time.Millisecond,
+		maxBackoff: time.Second,
+	}
+	for _, opt := range o {
+		opt(l)
+	}
+
+	if l.minBackoff <= 0 || l.maxBackoff < l.minBackoff {
+		return nil, errInvalidBackoff
+	}
+
+	return l, nil
+}
+
+func newOwner() string {
+	bs := make([]byte, 16)
+	crand.Read(bs) // 按文档 Read 不会返回错误
+	return hex.EncodeToString(bs)
+}
+
+// ErrNotHeld 锁未被当前对象持有
+//
+// 在锁已经过期或是被其它持有者获取之后调用 [Lock.Unlock] 或 [Lock.Extend] 时返回。
+func ErrNotHeld() error { return errNotHeld }
+
+// Key 锁在缓存中的键名
+func (l *Lock) Key() string { return l.key }
+
+// TryLock 尝试获取锁
+//
+// 锁已经被持有（包括被当前对象持有）时返回 false，不会阻塞。
+func (l *Lock) TryLock() (bool, error) {
+	switch err := l.cache.Add(l.key, l.owner, l.ttl); {
+	case errors.Is(err, cache.ErrNotStored()):
+		return false, nil
+	case err != nil:
+		return false, err
+	default:
+		return true, nil
+	}
+}
+
+// Lock 获取锁
+//
+// 锁被持有时会按 [WithBackoff] 指定的间隔重试，直到获取成功或是 ctx 被取消。
+func (l *Lock) Lock(ctx context.Context) error {
+	backoff := l.minBackoff
+	for {
+		ok, err := l.TryLock()
+		if err != nil || ok {
+			return err
+		}
+
+		// 在 [backoff/2, backoff] 之间抖动，避免多个等待者同时重试。
+		d := backoff/2 + rand.N(backoff/2+1)
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return context.Cause(ctx)
+		case <-t.C:
+		}
+
+		if backoff = backoff * 2; backoff > l.maxBackoff {
+			backoff = l.maxBackoff
+		}
+	}
+}
+
+// 获取锁当前的版本标记，锁不存在或是由其它对象持有时返回 [ErrNotHeld]。
+func (l *Lock) token() (uint64, error) {
+	var owner string
+	token, err := l.cache.GetCAS(l.key, &owner)
+	switch {
+	case errors.Is(err, cache.ErrCacheMiss()):
+		return 0, ErrNotHeld()
+	case err != nil:
+		return 0, err
+	case owner != l.owner:
+		return 0, ErrNotHeld()
+	default:
+		return token, nil
+	}
+}
+
+// 将条件写入的错误转换为 [ErrNotHeld]
+func notHeld(err error) error {
+	if errors.Is(err, cache.ErrCacheMiss()) || errors.Is(err, cache.ErrNotStored()) {
+		return ErrNotHeld()
+	}
+	return err
+}
+
+// Unlock 释放锁
+//
+// 仅在锁依然由当前对象持有时才会删除，否则返回 [ErrNotHeld]。
+func (l *Lock) Unlock() error {
+	token, err := l.token()
+	if err != nil {
+		return err
+	}
+	return notHeld(l.cache.CompareAndDelete(l.key, token))
+}
+
+// Extend 将锁的有效时长重置为 ttl
+//
+// ttl 为 0 表示采用 [New] 指定的值。
+// 仅在锁依然由当前对象持有时才会续期，否则返回 [ErrNotHeld]。
+func (l *Lock) Extend(ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = l.ttl
+	}
+
+	token, err := l.token()
+	if err != nil {
+		return err
+	}
+	return notHeld(l.cache.CompareAndSwap(l.key, l.owner, token, ttl))
+}
+
+// Do 在持有锁期间执行 f
+//
+// 会先通过 [Lock.Lock] 获取锁，在 f 执行期间每隔 ttl/3 自动续期一次，f 返回之后释放锁。
+// 如果续期失败，传递给 f 的 ctx 会被取消，其 [context.Cause] 为续期时的错误。
+func (l *Lock) Do(ctx context.Context, f func(context.Context) error) error {
+	if err := l.Lock(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	exited := make(chan struct{})
+	go func() {
+		l.keepAlive(ctx, cancel)
+		close(exited)
+	}()
+
+	err := f(ctx)
+	cancel(nil)
+	<-exited // 确保不会在释放之后再续期
+	return errors.Join(err, l.Unlock())
+}
+
+func (l *Lock) keepAlive(ctx context.Context, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.Extend(0); err != nil {
+				cancel(err)
+				return
+			}
+		}
+	}
+}
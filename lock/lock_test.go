@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches/memory"
+	"github.com/issue9/cache/cachetest"
+)
+
+func TestLock(t *testing.T) {
+	a := assert.New(t, false)
+	c := memory.New()
+
+	l1, err := New(c, "lock", time.Second)
+	a.NotError(err).NotNil(l1).Equal(l1.Key(), "lock")
+	l2, err := New(c, "lock", time.Second)
+	a.NotError(err).NotNil(l2)
+
+	ok, err := l1.TryLock()
+	a.NotError(err).True(ok)
+	ok, err = l1.TryLock() // 不可重入
+	a.NotError(err).False(ok)
+	ok, err = l2.TryLock()
+	a.NotError(err).False(ok)
+
+	// 非持有者无法释放或续期
+	a.ErrorIs(l2.Unlock(), ErrNotHeld()).
+		ErrorIs(l2.Extend(0), ErrNotHeld()).
+		True(c.Exists("lock"))
+
+	a.NotError(l1.Extend(time.Minute))
+	ttl, err := c.TTL("lock")
+	a.NotError(err).True(ttl > time.Second)
+
+	a.NotError(l1.Unlock()).
+		False(c.Exists("lock")).
+		ErrorIs(l1.Unlock(), ErrNotHeld())
+
+	ok, err = l2.TryLock()
+	a.NotError(err).True(ok)
+	a.NotError(l2.Unlock())
+
+	// 过期之后被其它对象获取，原持有者不会误删
+	l3, err := New(c, "expire", 50*time.Millisecond)
+	a.NotError(err)
+	l4, err := New(c, "expire", time.Second)
+	a.NotError(err)
+	ok, err = l3.TryLock()
+	a.NotError(err).True(ok)
+	time.Sleep(100 * time.Millisecond)
+	ok, err = l4.TryLock()
+	a.NotError(err).True(ok)
+	a.ErrorIs(l3.Unlock(), ErrNotHeld()).
+		ErrorIs(l3.Extend(0), ErrNotHeld()).
+		True(c.Exists("expire"))
+
+	// 不支持 ConditionalCache
+	l, err := New(cachetest.Plain(c), "lock", time.Second)
+	a.ErrorIs(err, errors.ErrUnsupported).Nil(l)
+
+	// 包装之后的对象实现了 ConditionalCache，但底层并不支持
+	l, err = New(cache.Prefix(cachetest.Plain(c), "p:"), "lock", time.Second)
+	a.ErrorIs(err, errors.ErrUnsupported).Nil(l)
+	l, err = New(cache.Prefix(c, "p:"), "lock", time.Second)
+	a.NotError(err).NotNil(l)
+
+	l, err = New(c, "lock", 0)
+	a.ErrorIs(err, errInvalidTTL).Nil(l)
+	l, err = New(c, "lock", time.Nanosecond)
+	a.ErrorIs(err, errInvalidTTL).Nil(l)
+	l, err = New(c, "lock", time.Second, WithBackoff(time.Second, time.Millisecond))
+	a.Error(err).Nil(l)
+}
+
+func TestLock_Lock(t *testing.T) {
+	a := assert.New(t, false)
+	c := memory.New()
+
+	l1, err := New(c, "lock", time.Minute)
+	a.NotError(err)
+	l2, err := New(c, "lock", time.Minute, WithBackoff(time.Millisecond, 10*time.Millisecond))
+	a.NotError(err)
+
+	a.NotError(l1.Lock(context.Background()))
+
+	// 超时
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	a.ErrorIs(l2.Lock(ctx), context.DeadlineExceeded)
+
+	// 释放之后获取成功
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		a.NotError(l1.Unlock())
+	}()
+	start := time.Now()
+	a.NotError(l2.Lock(context.Background())).
+		True(time.Since(start) >= 50*time.Millisecond)
+	a.NotError(l2.Unlock())
+}
+
+func TestLock_Do(t *testing.T) {
+	a := assert.New(t, false)
+	c := memory.New()
+
+	l, err := New(c, "lock", 60*time.Millisecond)
+	a.NotError(err)
+
+	// 执行时间超过 ttl，由自动续期保证锁依然有效。
+	var running atomic.Bool
+	err = l.Do(context.Background(), func(ctx context.Context) error {
+		running.Store(true)
+		defer running.Store(false)
+
+		time.Sleep(200 * time.Millisecond)
+		a.NotError(ctx.Err()).True(c.Exists("lock"))
+		return nil
+	})
+	a.NotError(err).False(running.Load()).False(c.Exists("lock"))
+
+	// f 的错误
+	errF := errors.New("f")
+	a.ErrorIs(l.Do(context.Background(), func(context.Context) error { return errF }), errF).
+		False(c.Exists("lock"))
+
+	// 续期失败时取消 ctx
+	err = l.Do(context.Background(), func(ctx context.Context) error {
+		a.NotError(c.Delete("lock"))
+		<-ctx.Done()
+		a.ErrorIs(context.Cause(ctx), ErrNotHeld())
+		return nil
+	})
+	a.ErrorIs(err, ErrNotHeld())
+}
@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// 版本号的键名后缀
+const generationKey = "__gen__"
+
+// 保存在缓存中的版本号
+//
+// 版本号会被加在前缀之后，改变版本号即可让该前缀下的所有缓存项无法再被访问。
+type generation struct {
+	prefix string
+	ttl    time.Duration // 版本号在本地缓存的时间
+	last   atomic.Pointer[generationValue]
+}
+
+type generationValue struct {
+	base   string // 包含了版本号的前缀
+	expire time.Time
+}
+
+// Namespace 生成一个以版本号区分数据的缓存访问对象
+//
+// 版本号为保存在 c 中的随机值，键名为 name 加上 __gen__，
+// 所有的键名都会被转换为 name、版本号、冒号以及原键名的组合。
+// 调用返回对象的 [Cleanable.Clean] 仅改变版本号，之前的缓存项将无法再被访问，
+// 直到因为 TTL 过期或是被淘汰。这在 memcached 等不支持遍历键名的缓存中也同样有效。
+//
+// 版本号会在本地缓存 localTTL，在此期间不需要再从 c 中读取，
+// 但是其它进程中的 [Cleanable.Clean] 最多需要 localTTL 才能在当前进程中生效。
+// localTTL 小于等于 0 表示每次访问都需要读取版本号。
+//
+// 返回对象与 [Prefix] 的返回对象相同，可以与 [Prefix] 相互嵌套，
+// 也同样实现了 [ContextCache]、[BatchCache] 和 [StaleCache]。
+func Namespace(c Cache, name string, localTTL time.Duration) Cleanable {
+	if pp, ok := c.(*prefix); ok && pp.gen == nil {
+		name = pp.prefix + name
+		return &prefix{prefix: name, cache: pp.cache, codec: pp.codec, cleaner: pp.cleaner, gen: newGeneration(name, localTTL)}
+	}
+
+	pc, _ := c.(PrefixCleanable)
+	return &prefix{prefix: name, cache: AsContextCache(c), cleaner: pc, gen: newGeneration(name, localTTL)}
+}
+
+func newGeneration(prefix string, ttl time.Duration) *generation {
+	return &generation{prefix: prefix, ttl: ttl}
+}
+
+// 返回包含了版本号的前缀
+func (g *generation) base(ctx context.Context, c ContextCache) (string, error) {
+	last := g.last.Load()
+	if last != nil && time.Now().Before(last.expire) {
+		return last.base, nil
+	}
+
+	ver, err := loadVersion(ctx, c, g.prefix+generationKey)
+	if err != nil {
+		// 无法获取版本号时沿用上一次的值，由之后的操作返回错误，或是由 [StaleCache] 返回旧值。
+		if last != nil {
+			return last.base, nil
+		}
+		return "", err
+	}
+	return g.store(ver), nil
+}
+
+// 改变版本号
+func (g *generation) bump(ctx context.Context, c ContextCache) error {
+	ver, err := bumpVersion(ctx, c, g.prefix+generationKey)
+	if err != nil {
+		return err
+	}
+	g.store(ver)
+	return nil
+}
+
+func (g *generation) store(ver uint64) string {
+	b := g.prefix + strconv.FormatUint(ver, 10) + ":"
+	g.last.Store(&generationValue{base: b, expire: time.Now().Add(g.ttl)})
+	return b
+}
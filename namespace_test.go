@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches"
+	"github.com/issue9/cache/caches/memory"
+	"github.com/issue9/cache/cachetest"
+)
+
+func TestNamespace(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	n1 := cache.Namespace(d, "ns:", time.Minute)
+	n2 := cache.Namespace(d, "ns:", 500*time.Millisecond) // 模拟其它进程
+	a.NotError(n1.Set("k1", 1, cache.Forever))
+	a.True(d.Exists("ns:__gen__")).False(d.Exists("ns:k1"))
+
+	var v int
+	a.NotError(n2.Get("k1", &v)).Equal(v, 1)
+
+	a.NotError(n1.Clean())
+	a.False(n1.Exists("k1")).
+		True(n2.Exists("k1")) // 本地缓存的版本号未过期
+	time.Sleep(time.Second)
+	a.False(n2.Exists("k1"))
+
+	a.NotError(n2.Set("k1", 2, cache.Forever))
+	a.NotError(n1.Get("k1", &v)).Equal(v, 2)
+
+	cachetest.Batch(a, n1.(cache.BatchCache))
+}
+
+func TestNamespace_Prefix(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+
+	// Prefix 中的 Namespace
+	p := cache.Prefix(d, "p:")
+	n := cache.Namespace(p, "ns:", time.Minute)
+	a.NotError(n.Set("k1", 1, cache.Forever))
+	a.True(d.Exists("p:ns:__gen__"))
+	a.NotError(n.Clean())
+	a.False(n.Exists("k1"))
+
+	a.NotError(n.Set("k1", 1, cache.Forever))
+	a.NotError(p.Clean())
+	a.False(n.Exists("k1")).False(d.Exists("p:ns:__gen__"))
+
+	// Namespace 中的 Prefix
+	n = cache.Namespace(d, "ns:", time.Minute)
+	p = cache.Prefix(n, "p:")
+	a.NotError(p.Set("k1", 1, cache.Forever)).
+		NotError(n.Set("k1", 2, cache.Forever))
+
+	a.NotError(p.Clean())
+	var v int
+	a.False(p.Exists("k1")).
+		NotError(n.Get("k1", &v)).Equal(v, 2)
+
+	a.NotError(p.Set("k1", 1, cache.Forever))
+	a.NotError(n.Clean())
+	a.False(p.Exists("k1")).False(n.Exists("k1"))
+
+	// 底层不支持 PrefixCleanable 时，其中的 Prefix 同样以版本号区分数据。
+	n = cache.Namespace(cachetest.Plain(d), "ns2:", time.Minute)
+	p = cache.Prefix(n, "p:")
+	a.NotError(p.Set("k1", 1, cache.Forever)).
+		NotError(n.Set("k1", 2, cache.Forever))
+	a.NotError(p.Clean())
+	a.False(p.Exists("k1")).True(n.Exists("k1"))
+
+	a.NotError(p.Set("k1", 1, cache.Forever))
+	a.NotError(n.Clean())
+	a.False(p.Exists("k1"))
+}
+
+func TestNamespace_codec(t *testing.T) {
+	a := assert.New(t, false)
+
+	// Namespace 中的 Prefix 不应该再次编码
+	d := memory.New()
+	n := cache.Namespace(cache.PrefixWithCodec(d, "p:", caches.JSON), "ns:", time.Minute)
+	p := cache.Prefix(n, "x:")
+	a.NotError(p.Set("k", "v", cache.Forever))
+
+	var key string
+	for k, err := range d.(cache.Scanner).Keys("p:ns:*:x:k") {
+		a.NotError(err)
+		key = k
+	}
+	a.NotEmpty(key)
+
+	var raw []byte
+	a.NotError(d.Get(key, &raw)).Equal(string(raw), `"v"`)
+
+	var v string
+	a.NotError(p.Get("k", &v)).Equal(v, "v")
+}
+
+func TestNamespace_version(t *testing.T) {
+	a := assert.New(t, false)
+
+	keys := func(d cache.Driver) []string {
+		var ks []string
+		for k, err := range d.(cache.Scanner).Keys("ns:*:k1") {
+			a.NotError(err)
+			ks = append(ks, k)
+		}
+		return ks
+	}
+
+	// 多个进程得到相同的版本号
+	d := memory.New()
+	n1 := cache.Namespace(d, "ns:", time.Minute)
+	n2 := cache.Namespace(d, "ns:", time.Minute)
+	a.NotError(n1.Set("k1", 1, cache.Forever)).
+		Length(keys(d), 1).
+		True(n2.Exists("k1"))
+
+	a.NotError(n1.Clean())
+	a.NotError(n1.Set("k1", 2, cache.Forever)).
+		Length(keys(d), 2)
+
+	// 版本号被淘汰之后，旧数据不会再次生效。
+	n := cache.Namespace(d, "ns:", 0)
+	a.True(n.Exists("k1")).
+		NotError(d.Delete("ns:__gen__")).
+		False(n.Exists("k1"))
+}
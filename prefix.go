@@ -1,40 +1,318 @@
-// SPDX-FileCopyrightText: 2017-2024 caixw
+// SPDX-FileCopyrightText: 2017-2026 caixw
 //
 // SPDX-License-Identifier: MIT
 
 package cache
 
-import "time"
+import (
+	"context"
+	"errors"
+	"iter"
+	"strings"
+	"time"
+)
 
 type prefix struct {
-	prefix string
-	cache  Cache
+	prefix  string
+	cache   ContextCache
+	codec   Codec           // 为空表示由 cache 自行编码
+	cleaner PrefixCleanable // 为空表示不支持按前缀清除
+	gen     *generation     // 不为空表示以版本号区分数据
+}
+
+// PrefixCleanable 可以清除指定前缀缓存项的接口
+//
+// [Prefix] 返回对象的 [Cleanable.Clean] 会调用此方法。
+type PrefixCleanable interface {
+	// CleanPrefix 清除所有键名以 prefix 开头的缓存项
+	CleanPrefix(prefix string) error
+}
+
+// CleanPrefix 清除 c 中所有键名以 prefix 开头的缓存项
+//
+// 如果 c 未实现 [PrefixCleanable]，返回 [errors.ErrUnsupported]。
+// 装饰器可以通过此函数将 [PrefixCleanable.CleanPrefix] 转发给被装饰的对象。
+func CleanPrefix(c Cache, prefix string) error {
+	pc, ok := unwrapContextCache(c).(PrefixCleanable)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	return pc.CleanPrefix(prefix)
 }
 
 // Prefix 生成一个带有统一前缀名称的缓存访问对象
 //
+// 返回的对象同时也实现了 [ContextCache]，ctx 会被传递给 a。
+//
 //	c := memory.New(...)
 //	p := cache.Prefix(c, "prefix_")
 //	p.Get("k1") // 相当于 c.Get("prefix_k1")
-func Prefix(a Cache, p string) Cache {
+//
+// 返回对象的 [Cleanable.Clean] 仅清除该前缀下的缓存项。如果 a 实现了 [PrefixCleanable]，
+// 会调用 [PrefixCleanable.CleanPrefix] 删除这些缓存项；否则与 localTTL 为 0 的 [Namespace] 相同，
+// 以版本号区分数据，比如 memcached 等无法遍历键名的缓存，此时每次访问都需要额外读取一次版本号，
+// 可以改用 [Namespace] 并指定 localTTL 以减少读取次数。
+//
+// 装饰器即使实现了 [PrefixCleanable]，也可能因为被装饰的对象不支持而返回 [errors.ErrUnsupported]，
+// 这种情况下同样需要改用 [Namespace]。
+func Prefix(a Cache, p string) Cleanable { return PrefixWithCodec(a, p, nil) }
+
+// PrefixWithCodec 生成一个带有统一前缀名称和编码方式的缓存访问对象
+//
+// 值会先由 c 编码为 []byte 再交由 a 保存，对 a 的要求参考 [Codec]。
+//
+// c 为空表示由 a 自行编码，如果 a 本身也是由 [Prefix] 或 [PrefixWithCodec] 生成的对象，
+// 则沿用 a 的编码方式。
+func PrefixWithCodec(a Cache, p string, c Codec) Cleanable {
 	if pp, ok := a.(*prefix); ok {
-		return &prefix{prefix: pp.prefix + p, cache: pp.cache}
+		if pp.gen != nil { // 由 pp 负责编码，c 为空时不能再次采用 pp.codec。
+			if pp.cleaner == nil {
+				return &prefix{prefix: p, cache: pp, codec: c, gen: newGeneration(p, 0)}
+			}
+			return &prefix{prefix: p, cache: pp, codec: c, cleaner: pp}
+		}
+
+		if c == nil {
+			c = pp.codec
+		}
+		return &prefix{prefix: pp.prefix + p, cache: pp.cache, codec: c, cleaner: pp.cleaner}
+	}
+
+	pc, ok := a.(PrefixCleanable)
+	if !ok {
+		return &prefix{prefix: p, cache: AsContextCache(a), codec: c, gen: newGeneration(p, 0)}
 	}
-	return &prefix{prefix: p, cache: a}
+	return &prefix{prefix: p, cache: AsContextCache(a), codec: c, cleaner: pc}
 }
 
-func (p *prefix) Get(key string, v any) error { return p.cache.Get(p.prefix+key, v) }
+// 返回完整的键名前缀
+func (p *prefix) base(ctx context.Context) (string, error) {
+	if p.gen == nil {
+		return p.prefix, nil
+	}
+	return p.gen.base(ctx, p.cache)
+}
 
-func (p *prefix) Set(key string, val any, seconds time.Duration) error {
-	return p.cache.Set(p.prefix+key, val, seconds)
+func (p *prefix) Get(key string, v any) error { return p.GetContext(context.Background(), key, v) }
+
+func (p *prefix) Set(key string, val any, ttl time.Duration) error {
+	return p.SetContext(context.Background(), key, val, ttl)
 }
 
-func (p *prefix) Delete(key string) error { return p.cache.Delete(p.prefix + key) }
+func (p *prefix) Delete(key string) error { return p.DeleteContext(context.Background(), key) }
 
-func (p *prefix) Exists(key string) bool { return p.cache.Exists(p.prefix + key) }
+func (p *prefix) Exists(key string) bool { return p.ExistsContext(context.Background(), key) }
 
-func (p *prefix) Touch(key string, ttl time.Duration) error { return p.cache.Touch(p.prefix+key, ttl) }
+func (p *prefix) Touch(key string, ttl time.Duration) error {
+	return p.TouchContext(context.Background(), key, ttl)
+}
 
 func (p *prefix) Counter(key string, ttl time.Duration) (uint64, SetCounterFunc, bool, error) {
-	return p.cache.Counter(p.prefix+key, ttl)
+	return p.CounterContext(context.Background(), key, ttl)
+}
+
+func (p *prefix) Clean() error {
+	if p.gen != nil {
+		return p.gen.bump(context.Background(), p.cache)
+	}
+	return p.CleanPrefix("")
+}
+
+// CleanPrefix 实现 [PrefixCleanable] 接口
+//
+// 如果底层的缓存未实现 [PrefixCleanable]，返回 [errors.ErrUnsupported]。
+func (p *prefix) CleanPrefix(prefix string) error {
+	if p.cleaner == nil {
+		return errors.ErrUnsupported
+	}
+
+	b, err := p.base(context.Background())
+	if err != nil {
+		return err
+	}
+	return p.cleaner.CleanPrefix(b + prefix)
+}
+
+func (p *prefix) GetContext(ctx context.Context, key string, v any) error {
+	b, err := p.base(ctx)
+	if err != nil {
+		return err
+	}
+
+	if p.codec == nil {
+		return p.cache.GetContext(ctx, b+key, v)
+	}
+
+	var bs []byte
+	if err := p.cache.GetContext(ctx, b+key, &bs); err != nil {
+		return err
+	}
+	return p.codec.Unmarshal(bs, v)
+}
+
+func (p *prefix) SetContext(ctx context.Context, key string, val any, ttl time.Duration) error {
+	b, err := p.base(ctx)
+	if err != nil {
+		return err
+	}
+
+	if p.codec == nil {
+		return p.cache.SetContext(ctx, b+key, val, ttl)
+	}
+
+	bs, err := p.codec.Marshal(val)
+	if err != nil {
+		return err
+	}
+	return p.cache.SetContext(ctx, b+key, bs, ttl)
+}
+
+func (p *prefix) DeleteContext(ctx context.Context, key string) error {
+	b, err := p.base(ctx)
+	if err != nil {
+		return err
+	}
+	return p.cache.DeleteContext(ctx, b+key)
+}
+
+func (p *prefix) ExistsContext(ctx context.Context, key string) bool {
+	b, err := p.base(ctx)
+	return err == nil && p.cache.ExistsContext(ctx, b+key)
+}
+
+func (p *prefix) TouchContext(ctx context.Context, key string, ttl time.Duration) error {
+	b, err := p.base(ctx)
+	if err != nil {
+		return err
+	}
+	return p.cache.TouchContext(ctx, b+key, ttl)
+}
+
+func (p *prefix) CounterContext(ctx context.Context, key string, ttl time.Duration) (uint64, SetCounterFunc, bool, error) {
+	b, err := p.base(ctx)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	return p.cache.CounterContext(ctx, b+key, ttl)
+}
+
+// GetStale 实现 [StaleCache] 接口
+//
+// 仅在底层的缓存实现了 [StaleCache] 时有效，否则始终返回 [ErrCacheMiss]。
+func (p *prefix) GetStale(key string, v any) error {
+	sc, ok := asStaleCache(p.cache)
+	if !ok {
+		return ErrCacheMiss()
+	}
+
+	b, err := p.base(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if p.codec == nil {
+		return sc.GetStale(b+key, v)
+	}
+
+	var bs []byte
+	if err := sc.GetStale(b+key, &bs); err != nil {
+		return err
+	}
+	return p.codec.Unmarshal(bs, v)
+}
+
+func (p *prefix) GetMulti(items map[string]any) error {
+	b, err := p.base(context.Background())
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]string, len(items)) // 完整的键名与 items 中的键名
+	vals := make(map[string]any, len(items))
+	for key, v := range items {
+		k := b + key
+		keys[k] = key
+		if p.codec == nil {
+			vals[k] = v
+		} else {
+			vals[k] = &[]byte{}
+		}
+	}
+
+	if err := AsBatchCache(p.cache).GetMulti(vals); err != nil {
+		return err
+	}
+
+	for k, key := range keys {
+		v, found := vals[k]
+		if !found {
+			delete(items, key)
+			continue
+		}
+
+		if p.codec != nil {
+			if err := p.codec.Unmarshal(*v.(*[]byte), items[key]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *prefix) SetMulti(items map[string]any, ttl time.Duration) error {
+	b, err := p.base(context.Background())
+	if err != nil {
+		return err
+	}
+
+	vals := make(map[string]any, len(items))
+	for key, val := range items {
+		if p.codec != nil {
+			bs, err := p.codec.Marshal(val)
+			if err != nil {
+				return err
+			}
+			val = bs
+		}
+		vals[b+key] = val
+	}
+	return AsBatchCache(p.cache).SetMulti(vals, ttl)
+}
+
+func (p *prefix) DeleteMulti(keys ...string) error {
+	b, err := p.base(context.Background())
+	if err != nil {
+		return err
+	}
+
+	full := make([]string, 0, len(keys))
+	for _, key := range keys {
+		full = append(full, b+key)
+	}
+	return AsBatchCache(p.cache).DeleteMulti(full...)
+}
+
+// Keys 实现 [Scanner] 接口
+//
+// 返回的键名会去掉前缀，如果底层的缓存未实现 [Scanner]，会产生 [errors.ErrUnsupported]。
+func (p *prefix) Keys(pattern string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		s, ok := unwrapContextCache(p.cache).(Scanner)
+		if !ok {
+			yield("", errors.ErrUnsupported)
+			return
+		}
+
+		b, err := p.base(context.Background())
+		if err != nil {
+			yield("", err)
+			return
+		}
+
+		for k, err := range s.Keys(EscapePattern(b) + pattern) {
+			if !yield(strings.TrimPrefix(k, b), err) {
+				return
+			}
+		}
+	}
 }
@@ -1,16 +1,21 @@
-// SPDX-FileCopyrightText: 2017-2024 caixw
+// SPDX-FileCopyrightText: 2017-2026 caixw
 //
 // SPDX-License-Identifier: MIT
 
 package cache_test
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/issue9/assert/v4"
-	"github.com/issue9/cache"
 
+	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches"
 	"github.com/issue9/cache/caches/memory"
+	"github.com/issue9/cache/cachetest"
 )
 
 func TestPrefix(t *testing.T) {
@@ -27,3 +32,147 @@ func TestPrefix(t *testing.T) {
 	p2.Delete("key")
 	a.False(d.Exists("p1p2key"))
 }
+
+func TestPrefix_Context(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	p, ok := cache.Prefix(d, "p1").(cache.ContextCache)
+	a.True(ok).NotNil(p)
+
+	ctx := context.Background()
+	a.NotError(p.SetContext(ctx, "key", 5, cache.Forever)).
+		True(d.Exists("p1key")).
+		True(p.ExistsContext(ctx, "key"))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	var v int
+	a.ErrorIs(p.GetContext(canceled, "key", &v), context.Canceled)
+
+	a.NotError(p.DeleteContext(ctx, "key")).
+		False(d.Exists("p1key"))
+}
+
+func TestPrefixWithCodec(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	p1 := cache.PrefixWithCodec(d, "p1", caches.JSON)
+	a.NotError(p1.Set("key", map[string]int{"k": 1}, cache.Forever))
+	var raw string
+	a.NotError(d.Get("p1key", &raw)).Equal(raw, `{"k":1}`)
+
+	var v map[string]int
+	a.NotError(p1.Get("key", &v)).Equal(v, map[string]int{"k": 1})
+	a.ErrorIs(p1.Get("not-exists", &v), cache.ErrCacheMiss())
+
+	// 沿用 p1 的编码方式
+	p2 := cache.Prefix(p1, "p2")
+	a.NotError(p2.Set("key", "str", cache.Forever))
+	a.NotError(d.Get("p1p2key", &raw)).Equal(raw, `"str"`)
+
+	// 计数器不受影响
+	n, set, _, err := p2.Counter("n", cache.Forever)
+	a.NotError(err).Zero(n)
+	n, err = set(5)
+	a.NotError(err).Equal(n, 5)
+	var num int
+	a.NotError(p2.Get("n", &num)).Equal(num, 5)
+}
+
+func TestPrefix_Clean(t *testing.T) {
+	a := assert.New(t, false)
+
+	// 底层实现了 PrefixCleanable
+	d := memory.New()
+	p1 := cache.Prefix(d, "p1:")
+	p2 := cache.Prefix(d, "p2:")
+	a.NotError(p1.Set("k1", 1, cache.Forever)).
+		NotError(p2.Set("k1", 2, cache.Forever)).
+		NotError(d.Set("k1", 3, cache.Forever))
+
+	a.NotError(p1.Clean())
+	a.False(p1.Exists("k1")).
+		False(d.Exists("p1:k1")).
+		True(p2.Exists("k1")).
+		True(d.Exists("k1"))
+
+	// 嵌套
+	p3 := cache.Prefix(p2, "p3:")
+	a.NotError(p3.Set("k1", 3, cache.Forever)).True(d.Exists("p2:p3:k1"))
+	a.NotError(p3.Clean())
+	a.False(p3.Exists("k1")).True(p2.Exists("k1"))
+	a.NotError(p3.Set("k1", 3, cache.Forever))
+	a.NotError(p2.Clean())
+	a.False(p3.Exists("k1")).False(p2.Exists("k1"))
+
+	// 不支持 PrefixCleanable，以版本号区分数据。
+	c := cachetest.Plain(d)
+	p1 = cache.Prefix(c, "p1:")
+	p2 = cache.Prefix(c, "p2:")
+	a.NotError(p1.Set("k1", 1, cache.Forever)).
+		NotError(p2.Set("k1", 2, cache.Forever)).
+		True(d.Exists("p1:__gen__")).
+		False(d.Exists("p1:k1"))
+	a.NotError(p1.Clean())
+	a.False(p1.Exists("k1")).True(p2.Exists("k1"))
+	a.ErrorIs(cache.CleanPrefix(c, "p1:"), errors.ErrUnsupported)
+
+	// 嵌套
+	p3 = cache.Prefix(p2, "p3:")
+	a.NotError(p3.Set("k1", 3, cache.Forever))
+	a.NotError(p2.Clean())
+	a.False(p3.Exists("k1"))
+}
+
+func TestPrefix_GetItem(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	p := cache.PrefixWithCodec(d, "p:", caches.JSON)
+	a.NotError(p.Set("k1", []int{1, 2}, time.Minute))
+
+	v, item, err := cache.GetItem[[]int](p, "k1")
+	a.NotError(err).Equal(v, []int{1, 2}).Equal(item.OriginalTTL, time.Minute)
+
+	ttl, err := p.(cache.ItemCache).TTL("k1")
+	a.NotError(err).True(ttl > 0 && ttl <= time.Minute)
+
+	_, _, err = cache.GetItem[int](p, "not-exists")
+	a.ErrorIs(err, cache.ErrCacheMiss())
+
+	// 不支持
+	_, _, err = cache.GetItem[int](cachetest.Plain(d), "k1")
+	a.ErrorIs(err, errors.ErrUnsupported)
+	_, _, err = cache.GetItem[int](cache.Prefix(cachetest.Plain(d), "p:"), "k1")
+	a.ErrorIs(err, errors.ErrUnsupported)
+}
+
+func TestPrefix_Conditional(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	cachetest.Conditional(a, cache.Prefix(d, "p:"))
+	cachetest.Conditional(a, cache.PrefixWithCodec(d, "p:", caches.JSON))
+	cachetest.Conditional(a, cache.Namespace(d, "ns:", time.Minute))
+
+	// 不支持
+	p := cache.Prefix(cachetest.Plain(d), "p:").(cache.ConditionalCache)
+	a.ErrorIs(p.Add("k1", 1, cache.Forever), errors.ErrUnsupported)
+}
+
+func TestPrefix_SignedCounter(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	cachetest.SignedCounter(a, cache.Prefix(d, "p:"))
+	cachetest.SignedCounter(a, cache.Namespace(d, "ns:", time.Minute))
+
+	// 不支持
+	_, _, _, err := cache.SignedCounter(cachetest.Plain(d), "k1", cache.Forever, 0, 10)
+	a.ErrorIs(err, errors.ErrUnsupported)
+	p := cache.Prefix(cachetest.Plain(d), "p:").(cache.SignedCounterCache)
+	_, _, _, err = p.SignedCounter("k1", cache.Forever, 0, 10)
+	a.ErrorIs(err, errors.ErrUnsupported)
+}
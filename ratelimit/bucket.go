@@ -0,0 +1,147 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/issue9/cache"
+)
+
+// 令牌桶的 redis 脚本
+//
+// KEYS[1] 为保存状态的哈希表，ARGV 依次为桶的容量、生成一个令牌的时长（微秒）、当前时间（微秒）和 n，
+// 返回是否允许、剩余的令牌数量、距令牌填满的时长以及需要等待的时长，时长均为微秒。
+//
+// Lua 中的数值转换为字符串时只保留 14 位有效数字，所以保存的时间直接采用 ARGV 中的字符串。
+const tokenBucketScript = `
+local burst = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local n = tonumber(ARGV[4])
+
+local tokens = burst
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+if state[1] then
+    tokens = math.min(burst, tonumber(state[1]) + math.max(0, now - tonumber(state[2])) / interval)
+end
+
+local allowed = 0
+local retry = 0
+if tokens >= n then
+    tokens = tokens - n
+    allowed = 1
+    redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', ARGV[3])
+    redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) * interval / 1000) + 1)
+elseif n <= burst then
+    retry = math.ceil((n - tokens) * interval)
+end
+return {allowed, math.floor(tokens), math.ceil((burst - tokens) * interval), retry}
+`
+
+// 令牌桶的状态
+type bucket struct {
+	Tokens  float64
+	Updated int64 // 微秒
+}
+
+type tokenBucket struct {
+	cache    cache.ConditionalCache
+	client   redis.Scripter
+	script   *redis.Script
+	burst    int
+	interval time.Duration
+}
+
+// TokenBucket 声明令牌桶的限流器
+//
+// 桶中最多保存 burst 个令牌，每隔 interval 生成一个令牌，每个配额消耗一个令牌。
+// 允许最多 burst 个配额的突发流量，长期来看速率不超过每 interval 一个配额。
+// burst 和 interval 必须大于 0，否则返回错误。
+//
+// 在 redis 中状态以哈希表保存，其它驱动需要实现 [cache.ConditionalCache]，
+// 否则返回 [errors.ErrUnsupported]。
+func TokenBucket(d cache.Driver, burst int, interval time.Duration) (Limiter, error) {
+	if burst <= 0 || interval <= 0 {
+		return nil, errInvalidLimit
+	}
+
+	l := &tokenBucket{burst: burst, interval: interval}
+	if s := scripter(d); s != nil {
+		l.client = s
+		l.script = redis.NewScript(tokenBucketScript)
+	} else if cc, ok := d.(cache.ConditionalCache); ok {
+		l.cache = cc
+	} else {
+		return nil, errors.ErrUnsupported
+	}
+	return l, nil
+}
+
+func (l *tokenBucket) Allow(ctx context.Context, key string) (*Result, error) {
+	return l.AllowN(ctx, key, 1)
+}
+
+func (l *tokenBucket) AllowN(ctx context.Context, key string, n int) (*Result, error) {
+	if err := checkN(n); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var allowed bool
+	var tokens int
+	var reset, retry time.Duration
+
+	if l.client != nil {
+		args := []any{l.burst, l.interval.Microseconds(), now.UnixMicro(), n}
+		rslt, err := l.script.Run(ctx, l.client, []string{key}, args...).Int64Slice()
+		if err != nil {
+			return nil, err
+		}
+		allowed, tokens, reset, retry = rslt[0] == 1, int(rslt[1]), micro(rslt[2]), micro(rslt[3])
+	} else {
+		ts := now.UnixMicro()
+		interval := float64(l.interval.Microseconds())
+		burst := float64(l.burst)
+		err := update(ctx, l.cache, key, func(b bucket, found bool) (bucket, time.Duration, bool) {
+			t := burst
+			if found {
+				t = min(burst, b.Tokens+float64(max(0, ts-b.Updated))/interval)
+			}
+
+			allowed = t >= float64(n)
+			retry = 0
+			if allowed {
+				t -= float64(n)
+			} else if n <= l.burst {
+				retry = micro(int64(math.Ceil((float64(n) - t) * interval)))
+			}
+
+			tokens = int(t)
+			reset = micro(int64(math.Ceil((burst - t) * interval)))
+			// 令牌填满之后的状态与不存在相同
+			return bucket{Tokens: t, Updated: ts}, reset + time.Millisecond, allowed
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !allowed && n > l.burst {
+		retry = reset
+	}
+	return &Result{
+		Allowed:    allowed,
+		Limit:      l.burst,
+		Remaining:  tokens,
+		Reset:      now.Add(reset),
+		RetryAfter: retry,
+	}, nil
+}
@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache/caches/memory"
+	"github.com/issue9/cache/cachetest"
+)
+
+func TestTokenBucket(t *testing.T) {
+	a := assert.New(t, false)
+	ctx := context.Background()
+
+	for name, d := range drivers(a) {
+		t.Run(name, func(t *testing.T) {
+			a := assert.New(t, false)
+
+			l, err := TokenBucket(d, 3, 100*time.Millisecond)
+			a.NotError(err).NotNil(l)
+			key := "bucket-" + time.Now().Format(time.RFC3339Nano)
+
+			r, err := l.AllowN(ctx, key, 3)
+			a.NotError(err).Equal(r.Limit, 3)
+			assertResult(a, r, true, 0)
+			a.True(r.Reset.After(time.Now().Add(200 * time.Millisecond)))
+
+			r, err = l.Allow(ctx, key)
+			a.NotError(err)
+			assertResult(a, r, false, 0)
+			a.True(r.RetryAfter <= 100*time.Millisecond)
+
+			r, err = l.AllowN(ctx, key, 4)
+			a.NotError(err)
+			assertResult(a, r, false, 0)
+
+			time.Sleep(110 * time.Millisecond)
+			r, err = l.Allow(ctx, key)
+			a.NotError(err)
+			assertResult(a, r, true, 0)
+
+			// 令牌填满之后不会超过 burst
+			time.Sleep(500 * time.Millisecond)
+			r, err = l.Allow(ctx, key)
+			a.NotError(err)
+			assertResult(a, r, true, 2)
+		})
+	}
+
+	l, err := TokenBucket(cachetest.Plain(memory.New()), 3, time.Second)
+	a.ErrorIs(err, errors.ErrUnsupported).Nil(l)
+
+	l, err = TokenBucket(memory.New(), 0, time.Second)
+	a.Error(err).Nil(l)
+	l, err = TokenBucket(memory.New(), 3, time.Second)
+	a.NotError(err).NotNil(l)
+	r, err := l.AllowN(context.Background(), "n", -1)
+	a.Error(err).Nil(r)
+}
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/issue9/cache"
+)
+
+// 固定窗口的 redis 脚本
+//
+// KEYS[1] 为窗口的键名，ARGV 依次为 n、窗口时长（毫秒）和配额上限，
+// 返回是否允许以及当前窗口中已经消耗的配额。
+const fixedWindowScript = `
+local n = tonumber(ARGV[1])
+local count = redis.call('INCRBY', KEYS[1], n)
+if count == n then
+    redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+if count > tonumber(ARGV[3]) then
+    return {0, redis.call('DECRBY', KEYS[1], n)}
+end
+return {1, count}
+`
+
+type fixedWindow struct {
+	cache  cache.ContextCache
+	client redis.Scripter
+	script *redis.Script
+	limit  int
+	window time.Duration
+}
+
+// FixedWindow 声明固定窗口的限流器
+//
+// 时间被划分为长度为 window 的窗口，每个窗口内最多允许 limit 个配额，
+// 窗口结束之后配额全部恢复。实现简单且开销小，但是在窗口的边界处最多可能放行 2*limit 个请求。
+// limit 和 window 必须大于 0，否则返回错误。
+//
+// 窗口的状态以计数器的形式保存在键名为 key 加上窗口序号的缓存项中。
+func FixedWindow(d cache.Driver, limit int, window time.Duration) (Limiter, error) {
+	if limit <= 0 || window <= 0 {
+		return nil, errInvalidLimit
+	}
+
+	l := &fixedWindow{
+		cache:  cache.AsContextCache(d),
+		limit:  limit,
+		window: window,
+	}
+	if s := scripter(d); s != nil {
+		l.client = s
+		l.script = redis.NewScript(fixedWindowScript)
+	}
+	return l, nil
+}
+
+func (l *fixedWindow) Allow(ctx context.Context, key string) (*Result, error) {
+	return l.AllowN(ctx, key, 1)
+}
+
+func (l *fixedWindow) AllowN(ctx context.Context, key string, n int) (*Result, error) {
+	if err := checkN(n); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	index := now.UnixNano() / int64(l.window)
+	reset := time.Unix(0, (index+1)*int64(l.window))
+	key += ":" + strconv.FormatInt(index, 10)
+
+	var allowed bool
+	var count int
+	if l.client != nil {
+		rslt, err := l.script.Run(ctx, l.client, []string{key}, n, max(l.window.Milliseconds(), 1), l.limit).Int64Slice()
+		if err != nil {
+			return nil, err
+		}
+		allowed, count = rslt[0] == 1, int(rslt[1])
+	} else {
+		// 过期时间的精度由驱动决定，比如 memcached 会向上取整到秒，
+		// 键名中已经包含了窗口序号，所以过期时间长于窗口并不影响结果。
+		_, set, _, err := l.cache.CounterContext(ctx, key, l.window)
+		if err != nil {
+			return nil, err
+		}
+
+		c, err := set(n)
+		if err != nil {
+			return nil, err
+		}
+		if allowed = int(c) <= l.limit; !allowed {
+			if c, err = set(-n); err != nil {
+				return nil, err
+			}
+		}
+		count = int(c)
+	}
+
+	r := &Result{
+		Allowed:   allowed,
+		Limit:     l.limit,
+		Remaining: max(l.limit-count, 0),
+		Reset:     reset,
+	}
+	if !allowed {
+		r.RetryAfter = reset.Sub(now)
+	}
+	return r, nil
+}
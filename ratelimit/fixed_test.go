@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestFixedWindow(t *testing.T) {
+	a := assert.New(t, false)
+	ctx := context.Background()
+
+	for name, d := range drivers(a) {
+		t.Run(name, func(t *testing.T) {
+			a := assert.New(t, false)
+
+			// 窗口足够长，避免测试期间跨越窗口。
+			l, err := FixedWindow(d, 3, time.Hour)
+			a.NotError(err)
+			key := "fixed-" + time.Now().Format(time.RFC3339Nano)
+
+			r, err := l.Allow(ctx, key)
+			a.NotError(err).Equal(r.Limit, 3)
+			assertResult(a, r, true, 2)
+			r, err = l.AllowN(ctx, key, 2)
+			a.NotError(err)
+			assertResult(a, r, true, 0)
+
+			r, err = l.Allow(ctx, key)
+			a.NotError(err)
+			assertResult(a, r, false, 0)
+			a.True(r.RetryAfter <= time.Hour).
+				True(r.Reset.Before(time.Now().Add(time.Hour)))
+
+			// 其它 key 不受影响
+			r, err = l.AllowN(ctx, key+"-2", 3)
+			a.NotError(err)
+			assertResult(a, r, true, 0)
+
+			// 被拒绝的请求不消耗配额
+			l, err = FixedWindow(d, 3, time.Hour)
+			a.NotError(err)
+			key += "-3"
+			r, err = l.AllowN(ctx, key, 4)
+			a.NotError(err)
+			assertResult(a, r, false, 3)
+			r, err = l.AllowN(ctx, key, 3)
+			a.NotError(err)
+			assertResult(a, r, true, 0)
+
+			r, err = l.AllowN(ctx, key, 0)
+			a.Error(err).Nil(r)
+		})
+	}
+
+	l, err := FixedWindow(nil, 0, time.Second)
+	a.Error(err).Nil(l)
+}
@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+// Package ratelimit 基于缓存的限流器
+//
+// 提供了固定窗口 [FixedWindow]、滑动窗口日志 [SlidingLog] 和令牌桶 [TokenBucket] 三种算法，
+// 状态保存在 [cache.Driver] 中，可以在多个进程之间共享。
+// 如果 [cache.Driver.Driver] 返回的是 redis 客户端，会以 Lua 脚本的形式原子地完成判断和更新，
+// 否则由 [cache.Cache.Counter] 或是 [cache.ConditionalCache] 保证原子性。
+//
+// 时间以调用者所在机器的时间为准，多个进程之间的时钟偏差会影响限流的精度。
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/issue9/localeutil"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/issue9/cache"
+)
+
+var (
+	errInvalidLimit = localeutil.Error("the quota and period of rate limiter must be greater than 0")
+	errInvalidN     = localeutil.Error("n must be greater than 0")
+)
+
+// Result 限流的结果
+//
+// 各字段可直接用于填充 X-RateLimit-* 和 Retry-After 报头。
+type Result struct {
+	// Allowed 是否允许此次请求
+	Allowed bool
+
+	// Limit 配额的上限
+	Limit int
+
+	// Remaining 剩余的配额
+	Remaining int
+
+	// Reset 配额完全恢复的时间
+	Reset time.Time
+
+	// RetryAfter 被拒绝时需要等待的时长
+	//
+	// 在此时长之后再次请求相同的配额才有可能被允许，允许时为 0。
+	RetryAfter time.Duration
+}
+
+// Limiter 限流器
+type Limiter interface {
+	// Allow 相当于 AllowN(ctx, key, 1)
+	Allow(ctx context.Context, key string) (*Result, error)
+
+	// AllowN 判断 key 在当前是否还有 n 个配额
+	//
+	// 允许时会扣除相应的配额，被拒绝时不会消耗配额。
+	// n 大于配额上限的请求永远不会被允许，n 小于等于 0 时返回错误。
+	AllowN(ctx context.Context, key string, n int) (*Result, error)
+}
+
+// 如果 d 的底层为 redis 客户端，返回该客户端。
+func scripter(d cache.Driver) redis.Scripter {
+	s, _ := d.Driver().(redis.Scripter)
+	return s
+}
+
+// 以 CAS 的方式更新 key 对应的状态
+//
+// f 根据旧的状态生成新的状态及其有效时长，found 表示旧的状态是否存在，返回的 write 表示是否需要写入。
+// 在写入时发生冲突会重新读取并调用 f，直到写入成功或是 ctx 被取消。
+func update[T any](ctx context.Context, c cache.ConditionalCache, key string, f func(old T, found bool) (val T, ttl time.Duration, write bool)) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var old T
+		token, err := c.GetCAS(key, &old)
+		found := err == nil
+		if err != nil && !errors.Is(err, cache.ErrCacheMiss()) {
+			return err
+		}
+
+		val, ttl, write := f(old, found)
+		if !write {
+			return nil
+		}
+
+		if found {
+			err = c.CompareAndSwap(key, val, token, ttl)
+		} else {
+			err = c.Add(key, val, ttl)
+		}
+		if !errors.Is(err, cache.ErrNotStored()) && !errors.Is(err, cache.ErrCacheMiss()) {
+			return err
+		}
+	}
+}
+
+func checkN(n int) error {
+	if n <= 0 {
+		return errInvalidN
+	}
+	return nil
+}
+
+// 将脚本返回的微秒转换为 [time.Duration]
+func micro(n int64) time.Duration { return time.Duration(n) * time.Microsecond }
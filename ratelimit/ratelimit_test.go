@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches/memory"
+	"github.com/issue9/cache/caches/redis"
+)
+
+const redisURL = "redis://localhost:6379?dial_timeout=1&db=1&read_timeout=1&write_timeout=1"
+
+// 返回用于测试的驱动，分别对应通用的实现和 redis 脚本。
+//
+// 无法连接 redis 时，仅返回通用的实现。
+func drivers(a *assert.Assertion) map[string]cache.Driver {
+	ds := map[string]cache.Driver{"memory": memory.New()}
+
+	r, err := redis.NewFromURL(redisURL)
+	a.NotError(err).NotNil(r)
+	a.TB().Cleanup(func() { r.Close() })
+	if err := r.Ping(); err != nil {
+		a.TB().Logf("忽略 redis 的测试：%v", err)
+	} else {
+		ds["redis"] = r
+	}
+
+	return ds
+}
+
+// 判断 r 的各字段
+func assertResult(a *assert.Assertion, r *Result, allowed bool, remaining int) {
+	a.TB().Helper()
+
+	a.NotNil(r).
+		Equal(r.Allowed, allowed).
+		Equal(r.Remaining, remaining).
+		True(r.Reset.After(time.Now().Add(-time.Millisecond)))
+
+	if allowed {
+		a.Zero(r.RetryAfter)
+	} else {
+		a.True(r.RetryAfter > 0)
+	}
+}
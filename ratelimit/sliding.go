@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/issue9/cache"
+)
+
+// 滑动窗口日志的 redis 脚本
+//
+// KEYS[1] 为保存日志的有序集合，ARGV 依次为当前时间（微秒）、窗口时长（微秒）、配额上限、n、
+// 成员名称的前缀、窗口的起始时间（微秒）以及有序集合的有效时长（毫秒），
+// 返回是否允许、窗口中已经消耗的配额、距配额完全恢复的时长以及需要等待的时长，时长均为微秒。
+//
+// Lua 中的数值转换为字符串时只保留 14 位有效数字，所以传给 redis 的时间均直接采用 ARGV 中的字符串。
+const slidingLogScript = `
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local n = tonumber(ARGV[4])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[6])
+local count = redis.call('ZCARD', KEYS[1])
+
+local allowed = 0
+local retry = 0
+if count + n <= limit then
+    for i = 1, n do
+        redis.call('ZADD', KEYS[1], ARGV[1], ARGV[5] .. i)
+    end
+    redis.call('PEXPIRE', KEYS[1], ARGV[7])
+    count = count + n
+    allowed = 1
+elseif n <= limit then
+    local i = count + n - limit - 1
+    local e = redis.call('ZRANGE', KEYS[1], i, i, 'WITHSCORES')
+    retry = tonumber(e[2]) + window - now
+end
+
+local reset = 0
+local last = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+if last[2] then
+    reset = tonumber(last[2]) + window - now
+end
+return {allowed, count, reset, retry}
+`
+
+type slidingLog struct {
+	cache  cache.ConditionalCache
+	client redis.Scripter
+	script *redis.Script
+	limit  int
+	window time.Duration
+}
+
+// SlidingLog 声明滑动窗口日志的限流器
+//
+// 记录每一个配额被消耗的时间，任意长度为 window 的时间段内最多允许 limit 个配额。
+// 相比 [FixedWindow] 更加精确，但是每个 key 需要保存最多 limit 条记录。
+// limit 和 window 必须大于 0，否则返回错误。
+//
+// 在 redis 中日志以有序集合保存，其它驱动需要实现 [cache.ConditionalCache]，
+// 否则返回 [errors.ErrUnsupported]。
+func SlidingLog(d cache.Driver, limit int, window time.Duration) (Limiter, error) {
+	if limit <= 0 || window <= 0 {
+		return nil, errInvalidLimit
+	}
+
+	l := &slidingLog{limit: limit, window: window}
+	if s := scripter(d); s != nil {
+		l.client = s
+		l.script = redis.NewScript(slidingLogScript)
+	} else if cc, ok := d.(cache.ConditionalCache); ok {
+		l.cache = cc
+	} else {
+		return nil, errors.ErrUnsupported
+	}
+	return l, nil
+}
+
+func (l *slidingLog) Allow(ctx context.Context, key string) (*Result, error) {
+	return l.AllowN(ctx, key, 1)
+}
+
+func (l *slidingLog) AllowN(ctx context.Context, key string, n int) (*Result, error) {
+	if err := checkN(n); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var allowed bool
+	var count int
+	var reset, retry time.Duration
+
+	if l.client != nil {
+		member := strconv.FormatUint(rand.Uint64(), 36) + ":"
+		ts, window := now.UnixMicro(), l.window.Microseconds()
+		args := []any{ts, window, l.limit, n, member, ts - window, max(l.window.Milliseconds(), 1)}
+		rslt, err := l.script.Run(ctx, l.client, []string{key}, args...).Int64Slice()
+		if err != nil {
+			return nil, err
+		}
+		allowed, count, reset, retry = rslt[0] == 1, int(rslt[1]), micro(rslt[2]), micro(rslt[3])
+	} else {
+		ts := now.UnixMicro()
+		window := l.window.Microseconds()
+		err := update(ctx, l.cache, key, func(log []int64, _ bool) ([]int64, time.Duration, bool) {
+			// 日志按时间升序排列，去掉已经移出窗口的记录。
+			i := 0
+			for i < len(log) && log[i] <= ts-window {
+				i++
+			}
+			log = log[i:]
+
+			allowed = len(log)+n <= l.limit
+			retry = 0
+			if allowed {
+				for range n {
+					log = append(log, ts)
+				}
+			} else if n <= l.limit {
+				retry = micro(log[len(log)+n-l.limit-1] + window - ts)
+			}
+
+			count = len(log)
+			reset = 0
+			if count > 0 {
+				reset = micro(log[count-1] + window - ts)
+			}
+			return log, l.window, allowed
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !allowed && n > l.limit {
+		retry = reset
+	}
+	return &Result{
+		Allowed:    allowed,
+		Limit:      l.limit,
+		Remaining:  max(l.limit-count, 0),
+		Reset:      now.Add(reset),
+		RetryAfter: retry,
+	}, nil
+}
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache/caches/memory"
+	"github.com/issue9/cache/cachetest"
+)
+
+func TestSlidingLog(t *testing.T) {
+	a := assert.New(t, false)
+	ctx := context.Background()
+
+	for name, d := range drivers(a) {
+		t.Run(name, func(t *testing.T) {
+			a := assert.New(t, false)
+
+			l, err := SlidingLog(d, 3, 200*time.Millisecond)
+			a.NotError(err).NotNil(l)
+			key := "sliding-" + time.Now().Format(time.RFC3339Nano)
+
+			r, err := l.Allow(ctx, key)
+			a.NotError(err).Equal(r.Limit, 3)
+			assertResult(a, r, true, 2)
+			time.Sleep(50 * time.Millisecond)
+			r, err = l.AllowN(ctx, key, 2)
+			a.NotError(err)
+			assertResult(a, r, true, 0)
+
+			r, err = l.Allow(ctx, key)
+			a.NotError(err)
+			assertResult(a, r, false, 0)
+			a.True(r.RetryAfter <= 150*time.Millisecond) // 第一个记录移出窗口即可
+
+			r, err = l.AllowN(ctx, key, 2)
+			a.NotError(err)
+			assertResult(a, r, false, 0)
+			a.True(r.RetryAfter > 150*time.Millisecond) // 需要等到后两个记录移出窗口
+
+			r, err = l.AllowN(ctx, key, 4)
+			a.NotError(err)
+			assertResult(a, r, false, 0)
+
+			time.Sleep(160 * time.Millisecond)
+			r, err = l.Allow(ctx, key)
+			a.NotError(err)
+			assertResult(a, r, true, 0)
+
+			time.Sleep(210 * time.Millisecond)
+			r, err = l.AllowN(ctx, key, 3)
+			a.NotError(err)
+			assertResult(a, r, true, 0)
+		})
+	}
+
+	l, err := SlidingLog(cachetest.Plain(memory.New()), 3, time.Second)
+	a.ErrorIs(err, errors.ErrUnsupported).Nil(l)
+
+	l, err = SlidingLog(memory.New(), 0, time.Second)
+	a.Error(err).Nil(l)
+	l, err = SlidingLog(memory.New(), 3, time.Second)
+	a.NotError(err).NotNil(l)
+	r, err := l.AllowN(context.Background(), "n", -1)
+	a.Error(err).Nil(r)
+}
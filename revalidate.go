@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/issue9/localeutil"
+)
+
+var errInvalidRevalidate = localeutil.Error("soft must be greater than 0 and less than hard")
+
+// 保存于缓存中的值及其软过期时间
+type revalidateEnvelope[T any] struct {
+	Val   T
+	Fresh time.Time // 在此时间之后，需要在后台刷新。
+}
+
+func (e *revalidateEnvelope[T]) value() T { return e.Val }
+
+// 正在后台刷新的缓存项
+var revalidating sync.Map
+
+// GetOrRevalidate 获取缓存项，在软过期之后返回旧值并在后台刷新
+//
+// 缓存项写入 soft 时间之后被视为需要刷新，此时依然返回旧值，
+// 同时在后台调用 init 重新生成并写入缓存，同一进程中对同一缓存项的刷新同时只会有一个；
+// 写入 hard 时间之后缓存项被真正回收，此时的行为与 [GetOrInit] 相同。
+// soft 必须大于 0 且小于 hard，否则返回错误，hard 为 [Forever] 表示永不回收。
+// 后台刷新失败时会保留旧值，直到 hard 过期。
+//
+// errlog 用于输出后台刷新时产生的错误，可以为空。
+//
+// 缓存中保存的是包含了值和软过期时间的对象，所以同一个 key 只能通过此函数访问。
+func GetOrRevalidate[T any](c Cache, key string, v *T, soft, hard time.Duration, init func(*T) error, errlog func(error)) error {
+	if soft <= 0 || (hard != Forever && soft >= hard) {
+		return errInvalidRevalidate
+	}
+
+	load := func(v *T) error {
+		if err := init(v); err != nil {
+			return err
+		}
+		return c.Set(key, &revalidateEnvelope[T]{Val: *v, Fresh: time.Now().Add(soft)}, hard)
+	}
+
+	env := &revalidateEnvelope[T]{}
+	switch err := c.Get(key, env); {
+	case errors.Is(err, ErrCacheMiss()):
+		return loadShared(c, key, v, func() (any, error) { return *v, load(v) }, func(t any) error { return c.Get(key, t) }, env)
+	case err != nil:
+		return err
+	}
+
+	*v = env.Val
+	if time.Now().Before(env.Fresh) {
+		return nil
+	}
+
+	refresh := func() {
+		if err := load(new(T)); err != nil && errlog != nil {
+			errlog(err)
+		}
+	}
+	fk, ok := flightKeyOf(c, key)
+	if !ok {
+		go refresh()
+		return nil
+	}
+	if _, loaded := revalidating.LoadOrStore(fk, struct{}{}); !loaded {
+		go func() {
+			defer revalidating.Delete(fk)
+			refresh()
+		}()
+	}
+	return nil
+}
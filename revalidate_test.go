@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches/memory"
+)
+
+func TestGetOrRevalidate(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	var calls atomic.Int32
+	init := func(v *int) error {
+		time.Sleep(50 * time.Millisecond)
+		*v = int(calls.Add(1))
+		return nil
+	}
+
+	var v int
+	a.NotError(cache.GetOrRevalidate(d, "key", &v, 200*time.Millisecond, time.Minute, init, nil)).
+		Equal(v, 1).Equal(calls.Load(), 1)
+
+	// 未到软过期时间
+	a.NotError(cache.GetOrRevalidate(d, "key", &v, 200*time.Millisecond, time.Minute, init, nil)).
+		Equal(v, 1).Equal(calls.Load(), 1)
+
+	// 软过期之后返回旧值，并在后台刷新。
+	time.Sleep(300 * time.Millisecond)
+	for range 5 {
+		a.NotError(cache.GetOrRevalidate(d, "key", &v, 200*time.Millisecond, time.Minute, init, nil)).
+			Equal(v, 1)
+	}
+	time.Sleep(200 * time.Millisecond)
+	a.Equal(calls.Load(), 2)
+	a.NotError(cache.GetOrRevalidate(d, "key", &v, 200*time.Millisecond, time.Minute, init, nil)).
+		Equal(v, 2)
+
+	// 刷新失败，保留旧值，并通过 errlog 输出错误。
+	time.Sleep(300 * time.Millisecond)
+	failed := func(*int) error { return errors.New("failed") }
+	errs := make(chan error, 2)
+	errlog := func(err error) { errs <- err }
+	a.NotError(cache.GetOrRevalidate(d, "key", &v, 200*time.Millisecond, time.Minute, failed, errlog)).
+		Equal(v, 2)
+	time.Sleep(100 * time.Millisecond)
+	a.NotError(cache.GetOrRevalidate(d, "key", &v, 200*time.Millisecond, time.Minute, failed, errlog)).
+		Equal(v, 2)
+	a.ErrorString(<-errs, "failed")
+
+	// 硬过期之后同步初始化
+	a.ErrorString(cache.GetOrRevalidate(d, "key2", &v, 500*time.Millisecond, time.Second, failed, nil), "failed")
+	a.NotError(cache.GetOrRevalidate(d, "key2", &v, 500*time.Millisecond, time.Second, init, nil)).Equal(v, 3)
+	time.Sleep(1500 * time.Millisecond)
+	a.NotError(cache.GetOrRevalidate(d, "key2", &v, 500*time.Millisecond, time.Second, init, nil)).Equal(v, 4)
+
+	// 无效的参数
+	a.Error(cache.GetOrRevalidate(d, "key3", &v, 0, time.Second, init, nil)).
+		Error(cache.GetOrRevalidate(d, "key3", &v, time.Second, time.Second, init, nil)).
+		NotError(cache.GetOrRevalidate(d, "key3", &v, time.Second, cache.Forever, init, nil))
+}
@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"iter"
+	"strings"
+)
+
+// Scanner 可以遍历键名的缓存
+type Scanner interface {
+	// Keys 遍历所有与 pattern 匹配的键名
+	//
+	// pattern 采用与 redis 的 SCAN 命令相同的匹配规则：
+	// * 匹配任意数量的字符，? 匹配单个字符，[abc]、[^a] 和 [a-z] 匹配一组字符，
+	// \ 用于转义之后的字符。如果仅需要匹配前缀，可以使用 EscapePattern(prefix) + "*"。
+	//
+	// 遍历期间写入或删除的键名不一定会反映在结果中，部分实现中同一键名也可能被返回多次。
+	// 出错时会产生一个空的键名和错误，之后不再继续，
+	// 不支持遍历的实现会产生 [errors.ErrUnsupported]。
+	Keys(pattern string) iter.Seq2[string, error]
+}
+
+// EscapePattern 转义 s 中在 [Scanner.Keys] 的参数中有特殊含义的字符
+func EscapePattern(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches/memory"
+	"github.com/issue9/cache/cachetest"
+)
+
+func TestEscapePattern(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.Equal(cache.EscapePattern("abc"), "abc").
+		Equal(cache.EscapePattern("a*b?c[d]e\\f"), "a\\*b\\?c\\[d\\]e\\\\f").
+		Equal(cache.EscapePattern("前缀:"), "前缀:")
+}
+
+func TestPrefix_Keys(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	a.NotError(d.Set("keys:1", 1, cache.Forever))
+
+	p := cache.Prefix(d, "p[1]:")
+	cachetest.Keys(a, p)
+
+	// 以版本号区分
+	n := cache.Namespace(d, "ns:", 0)
+	cachetest.Keys(a, n)
+	a.NotError(n.Set("k1", 1, cache.Forever))
+	a.NotError(n.Clean())
+	for k := range n.(cache.Scanner).Keys("*") {
+		a.TB().Errorf("返回了已经失效的键名 %s", k)
+	}
+
+	// 不支持
+	p = cache.Prefix(cachetest.Plain(d), "p:")
+	for k, err := range p.(cache.Scanner).Keys("*") {
+		a.ErrorIs(err, errors.ErrUnsupported).Empty(k)
+	}
+}
@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/issue9/localeutil"
+)
+
+var errOutOfRange = localeutil.Error("counter out of range")
+
+// SetSignedCounterFunc 为有符号计数器增加数值的函数原型
+//
+// 与 [SetCounterFunc] 相同，但是数值可以为负数。
+// 如果操作之后的数值超出了范围，不会修改计数器，返回当前的数值以及 [ErrOutOfRange]。
+type SetSignedCounterFunc = func(n int64) (int64, error)
+
+// SignedCounterCache 支持有符号计数器的缓存
+type SignedCounterCache interface {
+	// SignedCounter 从 key 指向的值初始化一个有符号的计数器
+	//
+	// 与 [Cache.Counter] 相同，但是数值为 int64，减少时也不会被限制在零，
+	// 可以用于保存余额或是差值等可能为负数的值。
+	//
+	// lower 和 upper 为数值的范围（包含两者），操作之后的数值超出范围或是溢出时 f 返回 [ErrOutOfRange]。
+	// 不需要限制范围时可以分别指定为 [math.MinInt64] 和 [math.MaxInt64]。
+	// 计数器不存在时初始化为零，如果零不在范围之内，则初始化为离零最近的边界值。
+	// lower 大于 upper 时返回错误。
+	SignedCounter(key string, ttl time.Duration, lower, upper int64) (n int64, f SetSignedCounterFunc, exist bool, err error)
+}
+
+// ErrOutOfRange 有符号计数器的数值超出范围的错误
+func ErrOutOfRange() error { return errOutOfRange }
+
+// SignedCounter 初始化有符号的计数器
+//
+// 如果 c 未实现 [SignedCounterCache]，返回 [errors.ErrUnsupported]。
+func SignedCounter(c Cache, key string, ttl time.Duration, lower, upper int64) (int64, SetSignedCounterFunc, bool, error) {
+	sc, ok := unwrapContextCache(c).(SignedCounterCache)
+	if !ok {
+		return 0, nil, false, errors.ErrUnsupported
+	}
+	return sc.SignedCounter(key, ttl, lower, upper)
+}
+
+// SignedCounter 实现 [SignedCounterCache] 接口
+//
+// 如果底层的缓存未实现 [SignedCounterCache]，返回 [errors.ErrUnsupported]。
+func (p *prefix) SignedCounter(key string, ttl time.Duration, lower, upper int64) (int64, SetSignedCounterFunc, bool, error) {
+	sc, ok := unwrapContextCache(p.cache).(SignedCounterCache)
+	if !ok {
+		return 0, nil, false, errors.ErrUnsupported
+	}
+
+	b, err := p.base(context.Background())
+	if err != nil {
+		return 0, nil, false, err
+	}
+	return sc.SignedCounter(b+key, ttl, lower, upper)
+}
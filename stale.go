@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import "errors"
+
+// StaleCache 可以提供旧值的缓存
+//
+// 在后端出错时，实现者可以从 [Cache.Get] 中返回旧值并返回 [ErrStale]；
+// [GetOrInit] 在 init 失败时也会通过 [StaleCache.GetStale] 获取旧值。
+// [github.com/issue9/cache/caches/stale] 提供了一个实现。
+type StaleCache interface {
+	Cache
+
+	// GetStale 获取 key 最后一次已知的有效值
+	//
+	// 即使该值已经从缓存中过期或是删除，只要未超过实现者允许的时间依然可以获取，
+	// 不存在时返回 [ErrCacheMiss]。
+	GetStale(key string, v any) error
+}
+
+// 将 c 转换为 [StaleCache]
+func asStaleCache(c Cache) (StaleCache, bool) {
+	sc, ok := unwrapContextCache(c).(StaleCache)
+	return sc, ok
+}
+
+// 尝试从 c 中获取旧值，如果成功，返回包含了 err 的 [ErrStale]，否则原样返回 err。
+func serveStale(c Cache, key string, v any, err error) error {
+	if sc, ok := asStaleCache(c); ok && sc.GetStale(key, v) == nil {
+		return errors.Join(ErrStale(), err)
+	}
+	return err
+}
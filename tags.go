@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// 标签版本号的键名前缀
+const tagKeyPrefix = "__tag__:"
+
+// 保存于缓存中的值以及写入时各标签的版本号
+type taggedEnvelope[T any] struct {
+	Val  T
+	Tags map[string]uint64
+}
+
+// SetWithTags 写入带有标签的缓存项
+//
+// 每个标签在缓存中都有一个对应的版本号，写入时会记录下各标签当前的版本号，
+// 之后调用 [InvalidateTag] 会改变标签的版本号，从而使所有带该标签的缓存项失效。
+// 整个过程不需要遍历键名，所以适用于所有的 [Cache] 实现。
+//
+// 版本号为随机值，键名为 __tag__: 加上标签名。
+// 如果 c 是由 [Prefix] 生成的对象，版本号也会带上相同的前缀，即标签仅在同一前缀下有效。
+//
+// 缓存中保存的是包含了标签信息的对象，所以只能通过 [GetWithTags] 读取。
+func SetWithTags[T any](c Cache, key string, val T, ttl time.Duration, tags ...string) error {
+	env := &taggedEnvelope[T]{Val: val, Tags: make(map[string]uint64, len(tags))}
+	for _, tag := range tags {
+		ver, err := loadVersion(context.Background(), AsContextCache(c), tagKeyPrefix+tag)
+		if err != nil {
+			return err
+		}
+		env.Tags[tag] = ver
+	}
+	return c.Set(key, env, ttl)
+}
+
+// GetWithTags 读取由 [SetWithTags] 写入的缓存项
+//
+// 如果缓存项的任意一个标签已经通过 [InvalidateTag] 失效，
+// 会删除该缓存项并返回 [ErrCacheMiss]。
+func GetWithTags[T any](c Cache, key string, v *T) error {
+	env := &taggedEnvelope[T]{}
+	if err := c.Get(key, env); err != nil {
+		return err
+	}
+
+	for tag, ver := range env.Tags {
+		curr, err := loadVersion(context.Background(), AsContextCache(c), tagKeyPrefix+tag)
+		if err != nil {
+			return err
+		}
+
+		if curr != ver {
+			if err := c.Delete(key); err != nil {
+				return err
+			}
+			return ErrCacheMiss()
+		}
+	}
+
+	*v = env.Val
+	return nil
+}
+
+// InvalidateTag 使所有带有标签 tag 的缓存项失效
+//
+// 失效的缓存项并不会被立即删除，而是在下一次由 [GetWithTags] 读取时删除或是自然过期。
+func InvalidateTag(c Cache, tag string) error {
+	_, err := bumpVersion(context.Background(), AsContextCache(c), tagKeyPrefix+tag)
+	return err
+}
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches/memory"
+)
+
+func TestTags(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	a.NotError(cache.SetWithTags(d, "k1", 1, cache.Forever, "t1")).
+		NotError(cache.SetWithTags(d, "k2", 2, cache.Forever, "t1", "t2")).
+		NotError(cache.SetWithTags(d, "k3", 3, cache.Forever))
+
+	var v int
+	a.NotError(cache.GetWithTags(d, "k1", &v)).Equal(v, 1).
+		NotError(cache.GetWithTags(d, "k2", &v)).Equal(v, 2).
+		NotError(cache.GetWithTags(d, "k3", &v)).Equal(v, 3)
+	a.ErrorIs(cache.GetWithTags(d, "not-exists", &v), cache.ErrCacheMiss())
+
+	a.NotError(cache.InvalidateTag(d, "t2"))
+	a.NotError(cache.GetWithTags(d, "k1", &v)).Equal(v, 1).
+		ErrorIs(cache.GetWithTags(d, "k2", &v), cache.ErrCacheMiss()).
+		False(d.Exists("k2"))
+
+	a.NotError(cache.InvalidateTag(d, "t1"))
+	a.ErrorIs(cache.GetWithTags(d, "k1", &v), cache.ErrCacheMiss()).
+		NotError(cache.GetWithTags(d, "k3", &v)).Equal(v, 3)
+
+	// 重新写入
+	a.NotError(cache.SetWithTags(d, "k1", 11, time.Minute, "t1"))
+	a.NotError(cache.GetWithTags(d, "k1", &v)).Equal(v, 11)
+
+	// 版本号被回收之后不会与之前的相同
+	a.NotError(d.Clean())
+	a.NotError(cache.InvalidateTag(d, "t1"))
+	a.NotError(cache.SetWithTags(d, "k1", 1, cache.Forever, "t1"))
+	a.NotError(d.Delete("__tag__:t1"))
+	a.ErrorIs(cache.GetWithTags(d, "k1", &v), cache.ErrCacheMiss())
+
+	// 不存在的标签
+	a.NotError(cache.InvalidateTag(d, "not-exists"))
+}
+
+func TestTags_Prefix(t *testing.T) {
+	a := assert.New(t, false)
+
+	d := memory.New()
+	p1 := cache.Prefix(d, "p1:")
+	p2 := cache.Prefix(d, "p2:")
+
+	a.NotError(cache.SetWithTags(p1, "k1", 1, cache.Forever, "t1")).
+		NotError(cache.SetWithTags(p2, "k1", 2, cache.Forever, "t1"))
+	a.True(d.Exists("p1:__tag__:t1")).True(d.Exists("p2:__tag__:t1"))
+
+	a.NotError(cache.InvalidateTag(p1, "t1"))
+
+	var v int
+	a.ErrorIs(cache.GetWithTags(p1, "k1", &v), cache.ErrCacheMiss()).
+		NotError(cache.GetWithTags(p2, "k1", &v)).Equal(v, 2)
+}
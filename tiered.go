@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+)
+
+type tiered struct {
+	l1, l2     Driver
+	ctx1, ctx2 ContextCache
+	ttl        time.Duration
+}
+
+// Tiered 声明由两级缓存组成的 [Driver]
+//
+// l1 一般为进程内的缓存，比如 memory；l2 一般为远程的缓存，比如 redis，是数据的权威来源。
+//
+// 读取时先从 l1 读取，未命中时再从 l2 读取，并将读取的值写入 l1；
+// 写入时先写入 l2 再写入 l1；Delete、Touch 和 Clean 会同时作用于两者。
+// 写入 l1 的缓存项，其过期时间不会超过 ttl，也不会超过该项在 l2 中剩余的有效时间。
+// 由于其它进程对 l2 的修改并不会通知到当前进程的 l1，
+// 所以 ttl 也是 l1 中的数据与 l2 可能不一致的最长时间。
+// ttl 为 [Forever] 表示 l1 中的项与 l2 拥有相同的过期时间。
+//
+// 从 l2 读取的项在写入 l1 时需要知道其剩余的有效时间，这要求 l2 实现了 [ItemCache]，
+// 否则只能采用 ttl，如果此时 ttl 也为 [Forever]，则不会写入 l1。
+//
+// Counter 仅作用于 l2，每次操作计数器时都会删除 l1 中的同名项，
+// 以保证通过 Get 读取到的是 l2 中的最新值。
+//
+// 返回对象同时实现了 [ContextCache]，[Driver.Driver] 的返回值与 l2 相同。
+func Tiered(l1, l2 Driver, ttl time.Duration) Driver {
+	return &tiered{
+		l1:   l1,
+		l2:   l2,
+		ctx1: AsContextCache(l1),
+		ctx2: AsContextCache(l2),
+		ttl:  ttl,
+	}
+}
+
+// 写入 l1 时采用的过期时间
+func (t *tiered) localTTL(ttl time.Duration) time.Duration {
+	if t.ttl == Forever || (ttl != Forever && ttl < t.ttl) {
+		return ttl
+	}
+	return t.ttl
+}
+
+func (t *tiered) Get(key string, v any) error { return t.GetContext(context.Background(), key, v) }
+
+func (t *tiered) GetContext(ctx context.Context, key string, v any) error {
+	if err := t.ctx1.GetContext(ctx, key, v); err == nil {
+		return nil
+	}
+
+	if err := t.ctx2.GetContext(ctx, key, v); err != nil {
+		return err
+	}
+
+	// 填充 l1，失败并不影响从 l2 读取的结果。
+	if ttl, ok := t.fillTTL(key); ok {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && !rv.IsNil() {
+			_ = t.ctx1.SetContext(ctx, key, rv.Elem().Interface(), ttl)
+		}
+	}
+	return nil
+}
+
+// 从 l2 填充 l1 时采用的过期时间，返回 false 表示不应该填充。
+func (t *tiered) fillTTL(key string) (time.Duration, bool) {
+	ic, ok := unwrapContextCache(t.l2).(ItemCache)
+	if !ok {
+		return t.ttl, t.ttl != Forever
+	}
+
+	switch ttl, err := ic.TTL(key); {
+	case err != nil:
+		return 0, false
+	case ttl < 0: // 未知
+		return t.ttl, t.ttl != Forever
+	default:
+		return t.localTTL(ttl), true
+	}
+}
+
+func (t *tiered) Set(key string, val any, ttl time.Duration) error {
+	return t.SetContext(context.Background(), key, val, ttl)
+}
+
+func (t *tiered) SetContext(ctx context.Context, key string, val any, ttl time.Duration) error {
+	if err := t.ctx2.SetContext(ctx, key, val, ttl); err != nil {
+		return errors.Join(err, t.ctx1.DeleteContext(ctx, key))
+	}
+
+	// l2 已经写入成功，l1 写入失败时仅删除 l1 中的旧值，之后的读取会从 l2 重新填充。
+	if err := t.ctx1.SetContext(ctx, key, val, t.localTTL(ttl)); err != nil {
+		return t.ctx1.DeleteContext(ctx, key)
+	}
+	return nil
+}
+
+func (t *tiered) Delete(key string) error { return t.DeleteContext(context.Background(), key) }
+
+func (t *tiered) DeleteContext(ctx context.Context, key string) error {
+	return errors.Join(t.ctx2.DeleteContext(ctx, key), t.ctx1.DeleteContext(ctx, key))
+}
+
+func (t *tiered) Exists(key string) bool { return t.ExistsContext(context.Background(), key) }
+
+func (t *tiered) ExistsContext(ctx context.Context, key string) bool {
+	return t.ctx1.ExistsContext(ctx, key) || t.ctx2.ExistsContext(ctx, key)
+}
+
+func (t *tiered) Touch(key string, ttl time.Duration) error {
+	return t.TouchContext(context.Background(), key, ttl)
+}
+
+func (t *tiered) TouchContext(ctx context.Context, key string, ttl time.Duration) error {
+	return errors.Join(t.ctx2.TouchContext(ctx, key, ttl), t.ctx1.TouchContext(ctx, key, t.localTTL(ttl)))
+}
+
+func (t *tiered) Counter(key string, ttl time.Duration) (uint64, SetCounterFunc, bool, error) {
+	return t.CounterContext(context.Background(), key, ttl)
+}
+
+func (t *tiered) CounterContext(ctx context.Context, key string, ttl time.Duration) (uint64, SetCounterFunc, bool, error) {
+	if err := t.ctx1.DeleteContext(ctx, key); err != nil {
+		return 0, nil, false, err
+	}
+
+	n, f, exist, err := t.ctx2.CounterContext(ctx, key, ttl)
+	if err != nil {
+		return 0, nil, false, err
+	}
+
+	return n, func(n int) (uint64, error) {
+		v, err := f(n)
+		if err != nil {
+			return v, err
+		}
+		return v, t.ctx1.DeleteContext(ctx, key)
+	}, exist, nil
+}
+
+func (t *tiered) Clean() error { return errors.Join(t.l2.Clean(), t.l1.Clean()) }
+
+func (t *tiered) CleanPrefix(prefix string) error {
+	return errors.Join(CleanPrefix(t.l2, prefix), CleanPrefix(t.l1, prefix))
+}
+
+func (t *tiered) Ping() error { return errors.Join(t.l2.Ping(), t.l1.Ping()) }
+
+func (t *tiered) Close() error { return errors.Join(t.l2.Close(), t.l1.Close()) }
+
+func (t *tiered) Driver() any { return t.l2.Driver() }
@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/cache"
+	"github.com/issue9/cache/caches/memory"
+	"github.com/issue9/cache/cachetest"
+)
+
+func TestTiered(t *testing.T) {
+	a := assert.New(t, false)
+
+	l1, l2 := memory.New(), memory.New()
+	c := cache.Tiered(l1, l2, time.Minute)
+	a.NotNil(c).Equal(c.Driver(), l2.Driver())
+
+	cachetest.Basic(a, c)
+	cachetest.Object(a, c)
+	cachetest.Counter(a, c)
+
+	// 写入两级缓存
+	a.NotError(c.Set("k1", 1, cache.Forever)).
+		True(l1.Exists("k1")).
+		True(l2.Exists("k1"))
+
+	// 从 l2 填充 l1
+	a.NotError(l2.Set("k2", 2, cache.Forever)).False(l1.Exists("k2"))
+	var v int
+	a.NotError(c.Get("k2", &v)).Equal(v, 2).True(l1.Exists("k2"))
+
+	// l1 优先
+	a.NotError(l1.Set("k2", 3, cache.Forever))
+	a.NotError(c.Get("k2", &v)).Equal(v, 3)
+
+	// 删除两级缓存
+	a.NotError(c.Delete("k2")).
+		False(l1.Exists("k2")).
+		False(l2.Exists("k2")).
+		ErrorIs(c.Get("k2", &v), cache.ErrCacheMiss())
+
+	// Counter 作用于 l2
+	a.NotError(c.Set("n", 5, cache.Forever))
+	n, set, exist, err := c.Counter("n", cache.Forever)
+	a.NotError(err).True(exist).Equal(n, 5).False(l1.Exists("n"))
+	a.NotError(c.Get("n", &v)).Equal(v, 5).True(l1.Exists("n"))
+	n, err = set(3)
+	a.NotError(err).Equal(n, 8).False(l1.Exists("n"))
+	a.NotError(c.Get("n", &v)).Equal(v, 8)
+
+	// 按前缀清除两级缓存
+	p := cache.Prefix(c, "p:")
+	a.NotError(p.Set("k1", 1, cache.Forever)).
+		True(l1.Exists("p:k1")).
+		True(l2.Exists("p:k1"))
+	a.NotError(p.Clean()).
+		False(l1.Exists("p:k1")).
+		False(l2.Exists("p:k1")).
+		True(l2.Exists("k1"))
+
+	a.NotError(c.Clean()).
+		False(l1.Exists("k1")).
+		False(l2.Exists("k1"))
+	a.NotError(c.Ping())
+	a.NotError(c.Close())
+}
+
+func TestTiered_ttl(t *testing.T) {
+	a := assert.New(t, false)
+
+	l1, l2 := memory.New(), memory.New()
+	c := cache.Tiered(l1, l2, time.Second)
+
+	a.NotError(c.Set("k1", 1, cache.Forever))
+	time.Sleep(1500 * time.Millisecond)
+	a.False(l1.Exists("k1")).True(l2.Exists("k1")).True(c.Exists("k1"))
+
+	// ttl 小于 l1 的 ttl
+	a.NotError(c.Set("k2", 1, 100*time.Millisecond))
+	time.Sleep(200 * time.Millisecond)
+	a.False(l1.Exists("k2")).False(c.Exists("k2"))
+
+	// 从 l2 填充时不超过 l2 中剩余的有效时间
+	c = cache.Tiered(l1, l2, time.Hour)
+	a.NotError(l2.Set("k3", 3, 200*time.Millisecond))
+	var v int
+	a.NotError(c.Get("k3", &v)).Equal(v, 3).True(l1.Exists("k3"))
+	time.Sleep(300 * time.Millisecond)
+	a.False(l1.Exists("k3")).ErrorIs(c.Get("k3", &v), cache.ErrCacheMiss())
+
+	c = cache.Tiered(l1, l2, cache.Forever)
+	a.NotError(l2.Set("k4", 4, 200*time.Millisecond))
+	a.NotError(c.Get("k4", &v)).Equal(v, 4).True(l1.Exists("k4"))
+	time.Sleep(300 * time.Millisecond)
+	a.False(l1.Exists("k4"))
+
+	// l2 未实现 ItemCache，只能采用 ttl。
+	c = cache.Tiered(l1, cachetest.Plain(l2), time.Hour)
+	a.NotError(l2.Set("k5", 5, cache.Forever))
+	a.NotError(c.Get("k5", &v)).Equal(v, 5)
+	ttl, err := l1.TTL("k5")
+	a.NotError(err).True(ttl > 59*time.Minute)
+
+	c = cache.Tiered(l1, cachetest.Plain(l2), cache.Forever)
+	a.NotError(l2.Set("k6", 6, cache.Forever))
+	a.NotError(c.Get("k6", &v)).Equal(v, 6).False(l1.Exists("k6"))
+}
@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+)
+
+// 读取保存在 key 中的版本号
+//
+// 版本号不存在时以 [ConditionalCache.Add] 写入一个随机值，所以多个进程总是能得到相同的版本号，
+// 即使版本号被淘汰，新的版本号也不会与之前的相同，尚未过期的旧数据不会因此再次生效。
+// 如果 c 未实现 [ConditionalCache]，则直接写入，此时同时创建版本号的进程可能会得到不同的值。
+func loadVersion(ctx context.Context, c ContextCache, key string) (uint64, error) {
+	var ver uint64
+	if err := c.GetContext(ctx, key, &ver); !errors.Is(err, ErrCacheMiss()) {
+		return ver, err
+	}
+
+	ver = rand.Uint64()
+	if cc, ok := unwrapContextCache(c).(ConditionalCache); ok {
+		switch err := cc.Add(key, ver, Forever); {
+		case err == nil:
+			return ver, nil
+		case errors.Is(err, ErrNotStored()): // 已被其它进程创建
+			err = c.GetContext(ctx, key, &ver)
+			return ver, err
+		case !errors.Is(err, errors.ErrUnsupported):
+			return 0, err
+		}
+	}
+	return ver, c.SetContext(ctx, key, ver, Forever)
+}
+
+// 将 key 中的版本号改为新的随机值
+func bumpVersion(ctx context.Context, c ContextCache, key string) (uint64, error) {
+	ver := rand.Uint64()
+	if err := c.SetContext(ctx, key, ver, Forever); err != nil {
+		return 0, err
+	}
+	return ver, nil
+}
@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// InitOption [GetOrInit] 和 [GetOrInitContext] 的选项
+type InitOption func(*initOptions)
+
+type initOptions struct {
+	beta float64 // 大于 0 表示启用 XFetch
+}
+
+// 保存于缓存中的值以及生成该值所花费的时间
+type xfetchEnvelope[T any] struct {
+	Val    T
+	Delta  time.Duration // 执行 init 所花费的时间
+	Expire time.Time     // 过期时间，为零表示永不过期。
+}
+
+func buildInitOptions(o []InitOption) *initOptions {
+	opt := &initOptions{}
+	for _, f := range o {
+		f(opt)
+	}
+	return opt
+}
+
+// XFetch 启用概率性的提前过期
+//
+// 启用之后，缓存中会同时保存执行 init 所花费的时间，
+// 在过期之前的每次读取都有一定的概率提前执行 init 并重新写入缓存。
+// 越接近过期时间、init 的执行时间越长，该概率越高。
+// 多个进程之间无须任何协调便可以将重新生成的时间分散开，避免在过期的瞬间同时执行 init。
+//
+// beta 用于调整提前的程度，默认应该为 1，大于 1 更倾向于提前生成，小于等于 0 表示不启用。
+// 提前生成失败时依然返回缓存中的旧值。ttl 为 [Forever] 的缓存项不会提前生成。
+//
+// 缓存中保存的是包含了额外信息的对象，所以同一个 key 只能以相同的方式访问。
+// 算法来自论文 Optimal Probabilistic Cache Stampede Prevention。
+func XFetch(beta float64) InitOption {
+	return func(o *initOptions) { o.beta = beta }
+}
+
+func (e *xfetchEnvelope[T]) value() T { return e.Val }
+
+// 是否需要提前生成
+func (e *xfetchEnvelope[T]) early(now time.Time, beta float64) bool {
+	if e.Expire.IsZero() {
+		return false
+	}
+
+	r := 1 - rand.Float64() // (0,1]
+	gap := time.Duration(-float64(e.Delta) * beta * math.Log(r))
+	return !now.Add(gap).Before(e.Expire)
+}
+
+func getOrXFetch[T any](ctx context.Context, c ContextCache, cache Cache, key string, v *T, ttl time.Duration, init func(context.Context, *T) error, beta float64) error {
+	env := &xfetchEnvelope[T]{}
+	err := c.GetContext(ctx, key, env)
+	hit := err == nil
+	switch {
+	case hit:
+		if !env.early(time.Now(), beta) {
+			*v = env.Val
+			return nil
+		}
+	case !errors.Is(err, ErrCacheMiss()):
+		return err
+	}
+
+	var inited bool
+	load := func() (any, error) {
+		start := time.Now()
+		if err := init(ctx, v); err != nil {
+			return nil, err
+		}
+		inited = true
+
+		now := time.Now()
+		e := &xfetchEnvelope[T]{Val: *v, Delta: now.Sub(start)}
+		if ttl > 0 {
+			e.Expire = now.Add(ttl)
+		}
+		return *v, c.SetContext(ctx, key, e, ttl)
+	}
+
+	err = loadShared(cache, key, v, load, func(t any) error { return c.GetContext(ctx, key, t) }, env)
+	switch {
+	case err == nil || inited:
+		return err
+	case hit: // 提前生成失败，旧值依然有效。
+		*v = env.Val
+		return nil
+	}
+
+	if err = serveStale(cache, key, env, err); errors.Is(err, ErrStale()) {
+		*v = env.Val
+	}
+	return err
+}